@@ -0,0 +1,439 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/token"
+	"go/types"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/sivukhin/gomakus/src"
+)
+
+const (
+	warningMessage           = "potential append overwrite found"
+	unusedSuppressionMessage = "unused gomakus suppression directive"
+	incompleteMessage        = "gomakus analysis of the function was cut short"
+	incompleteCategory       = "incomplete"
+)
+
+// Analyzer reports potential append overwrites for every function declaration in the package
+var Analyzer = &analysis.Analyzer{
+	Name: "gomakus",
+	Doc:  "find appends which can silently overwrite elements of the slice shared with another append",
+	URL:  "https://github.com/sivukhin/gomakus",
+	Run:  run,
+	// specs of the exported functions are passed to the dependent packages, so calls of them are not opaque
+	FactTypes: []analysis.Fact{new(funcSpecFact)},
+}
+
+var analyzerConfig Config
+
+func init() {
+	analyzerConfig.RegisterFlags(&Analyzer.Flags)
+}
+
+// Config holds analysis settings shared by the Analyzer and gomakus CLI
+type Config struct {
+	// NoReturnFuncs extends src.DefaultNoReturnFuncs with user-defined functions
+	NoReturnFuncs []string
+	// FuncSpecs extends src.DefaultFuncSpecCollection with user-defined specs (keyed by src.FuncName)
+	// They take precedence over the specs inferred for the functions of the analyzed packages
+	FuncSpecs map[string]src.FuncSpec
+	// ReportUnusedSuppressions enables reporting of the suppression directives which don't cover any warning
+	ReportUnusedSuppressions bool
+	// Limits bounds the analysis of every function: functions exceeding them are reported as incomplete
+	Limits src.Limits
+	// ReportUnsupported enables reporting of the functions which are skipped because of the unsupported constructs
+	ReportUnsupported bool
+	// Explain fills Trace and ConflictPoint of the warnings by enumeration of the execution traces (bounded by Limits.MaxTraces or DefaultExplainMaxTraces)
+	Explain bool
+	// Changed restricts validation to the functions overlapping with the changed lines and KeepChanged keeps only findings on the changed lines
+	// Specs are inferred for all functions anyway, so calls of the untouched functions within the validated ones aren't opaque
+	Changed ChangedLines
+}
+
+// DefaultExplainMaxTraces bounds the enumeration of the traces in Config.Explain mode when Limits.MaxTraces isn't set
+const DefaultExplainMaxTraces = 10000
+
+func (c *Config) RegisterFlags(flags *flag.FlagSet) {
+	flags.Func(
+		"no-return",
+		"comma-separated list of functions which never return in addition to panic, os.Exit, log.Fatal, etc.\n"+
+			"(e.g. github.com/acme/log.Die,(*github.com/acme/log.Logger).Die)",
+		func(value string) error {
+			for _, name := range strings.Split(value, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					c.NoReturnFuncs = append(c.NoReturnFuncs, name)
+				}
+			}
+			return nil
+		},
+	)
+	flags.Func(
+		"specs",
+		"path to the JSON file with specs of the functions which can't be inferred (can be repeated)",
+		func(value string) error {
+			specs, err := LoadFuncSpecs(value)
+			if err != nil {
+				return err
+			}
+			if c.FuncSpecs == nil {
+				c.FuncSpecs = make(map[string]src.FuncSpec, len(specs))
+			}
+			maps.Copy(c.FuncSpecs, specs)
+			return nil
+		},
+	)
+	flags.BoolVar(
+		&c.ReportUnusedSuppressions,
+		"report-unused-suppressions",
+		false,
+		"report //gomakus:ignore and //nolint:gomakus directives which don't suppress any warning",
+	)
+	flags.IntVar(
+		&c.Limits.MaxPoints,
+		"max-points",
+		0,
+		"maximum number of execution points in the analyzed function (0 means no limit)",
+	)
+	flags.DurationVar(
+		&c.Limits.Timeout,
+		"func-timeout",
+		0,
+		"maximum wall time of the analysis of single function (0 means no limit)",
+	)
+	flags.BoolVar(
+		&c.ReportUnsupported,
+		"report-unsupported",
+		false,
+		"report functions which can't be analyzed because of the unsupported constructs",
+	)
+}
+
+// Scopes creates root scopes for the analysis of single function; info can be nil - then all identifiers will be resolved by name
+func (c Config) Scopes(info *types.Info) src.Scopes {
+	scopes := src.NewScopes(src.DefaultFuncs)
+	if info != nil {
+		scopes = src.NewTypedScopes(src.DefaultFuncs, info)
+	}
+	if len(c.NoReturnFuncs) > 0 {
+		scopes.NoReturnFuncs = maps.Clone(src.DefaultNoReturnFuncs)
+		for _, name := range c.NoReturnFuncs {
+			scopes.NoReturnFuncs[name] = struct{}{}
+		}
+	}
+	return scopes
+}
+
+// Funcs registers functions with known specs on top of the defaults: standard library specs are overridden by the imported ones and both are overridden by the user-defined ones
+// Registered functions get consecutive ids starting from 1, and the next free id is returned
+func (c Config) Funcs(imported map[string]src.FuncSpec) (map[string]src.FuncId, src.FuncSpecCollection, src.FuncId) {
+	known := maps.Clone(src.StdFuncSpecs)
+	maps.Copy(known, imported)
+	maps.Copy(known, c.FuncSpecs)
+	names := make([]string, 0, len(known))
+	for name := range known {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	funcs := maps.Clone(src.DefaultFuncs)
+	specs := maps.Clone(src.DefaultFuncSpecCollection)
+	nextFuncId := src.FuncId(1)
+	for _, name := range names {
+		funcs[name] = nextFuncId
+		specs[nextFuncId] = known[name]
+		nextFuncId++
+	}
+	return funcs, specs, nextFuncId
+}
+
+// Warning represents single potential append overwrite found in the function
+// FuncDecl is nil for the function literals defined outside any function declaration
+// Incomplete is set instead of ValidationWarning for the informational warning about the function which analysis was cut short:
+// either it exceeded Config.Limits (error wraps src.ErrBudgetExceeded), its analysis failed internally (error wraps src.ErrInternal) or it contains unsupported construct (error is *src.UnsupportedError)
+// Variable is the source of the argument which elements can be overwritten (e.g. prefix for append(prefix, 1)) if it is known
+type Warning struct {
+	Pos               token.Pos
+	FuncDecl          *ast.FuncDecl
+	Execution         src.Execution
+	ValidationWarning src.ValidationWarning
+	Variable          string
+	Incomplete        error
+}
+
+// FuncName returns name of the function declaration where warning was found
+func (w Warning) FuncName() string {
+	if w.FuncDecl == nil {
+		return "func literal"
+	}
+	return w.FuncDecl.Name.Name
+}
+
+// AnalyzeFunc analyzes single function declaration: only default and user-defined specs are used, so calls of the module functions are opaque
+func AnalyzeFunc(config Config, fset *token.FileSet, info *types.Info, funcDecl *ast.FuncDecl) []Warning {
+	if funcDecl.Body == nil {
+		return nil
+	}
+	scopes := config.Scopes(info)
+	funcs, specs, _ := config.Funcs(nil)
+	scopes.Funcs = funcs
+	execution, err := src.ExecutionFromFunc(scopes, fset, funcDecl)
+	if err != nil {
+		return unsupportedWarnings(config, funcDecl, err)
+	}
+	return executionWarnings(config, specs, funcDecl, funcDecl, execution)
+}
+
+// AnalyzeFuncLit analyzes function literal defined outside any function declaration (e.g. var f = func() { ... })
+func AnalyzeFuncLit(config Config, fset *token.FileSet, info *types.Info, funcLit *ast.FuncLit) []Warning {
+	scopes := config.Scopes(info)
+	funcs, specs, _ := config.Funcs(nil)
+	scopes.Funcs = funcs
+	execution, err := src.ExecutionFromFuncLit(scopes, fset, funcLit)
+	if err != nil {
+		return unsupportedWarnings(config, nil, err)
+	}
+	return executionWarnings(config, specs, nil, funcLit, execution)
+}
+
+// unsupportedWarnings reports the function which execution can't be built if Config.ReportUnsupported is set
+func unsupportedWarnings(config Config, funcDecl *ast.FuncDecl, err error) []Warning {
+	var unsupportedErr *src.UnsupportedError
+	if !config.ReportUnsupported || !errors.As(err, &unsupportedErr) {
+		return nil
+	}
+	return []Warning{{Pos: unsupportedErr.Pos, FuncDecl: funcDecl, Incomplete: err}}
+}
+
+// executionWarnings validates execution together with executions of all function literals defined within it
+// Every execution is validated within its own budget, and warnings found before the budget is exceeded are kept
+// root is the function declaration or literal which contains the execution (it is used to find names of the aliased variables)
+func executionWarnings(
+	config Config,
+	specs src.FuncSpecCollection,
+	funcDecl *ast.FuncDecl,
+	root ast.Node,
+	execution src.Execution,
+) []Warning {
+	rootPos := execution.SourceCodeReferences.References[execution.RootPoint]
+	validationWarnings, err := src.ValidateExecutionWithin(src.NewBudget(context.Background(), config.Limits), specs, execution)
+	if config.Explain && len(validationWarnings) > 0 {
+		validationWarnings = explainWarnings(config, specs, execution, validationWarnings)
+	}
+	var warnings []Warning
+	for _, validationWarning := range validationWarnings {
+		pos, ok := execution.SourceCodeReferences.References[validationWarning.ExecutionPoint]
+		if !ok {
+			pos = rootPos
+		}
+		warnings = append(warnings, Warning{
+			Pos:               pos,
+			FuncDecl:          funcDecl,
+			Execution:         execution,
+			ValidationWarning: validationWarning,
+			Variable:          aliasedVariable(root, specs, execution, validationWarning.ExecutionPoint),
+		})
+	}
+	if err != nil {
+		warnings = append(warnings, Warning{Pos: rootPos, FuncDecl: funcDecl, Execution: execution, Incomplete: err})
+	}
+	for _, funcLit := range execution.FuncLits {
+		warnings = append(warnings, executionWarnings(config, specs, funcDecl, root, funcLit)...)
+	}
+	return warnings
+}
+
+// explainWarnings replaces warnings with the ones found by ValidateExecutionTraces at the same points, so they have Trace and ConflictPoint
+// Warnings which trace wasn't found within the limits are kept as is
+func explainWarnings(config Config, specs src.FuncSpecCollection, execution src.Execution, warnings []src.ValidationWarning) []src.ValidationWarning {
+	limits := config.Limits
+	if limits.MaxTraces == 0 {
+		limits.MaxTraces = DefaultExplainMaxTraces
+	}
+	traceWarnings, _ := src.ValidateExecutionTraces(src.NewBudget(context.Background(), limits), specs, execution)
+	explained := make(map[src.ExecutionPoint]src.ValidationWarning, len(traceWarnings))
+	for _, traceWarning := range traceWarnings {
+		explained[traceWarning.ExecutionPoint] = traceWarning
+	}
+	for i, warning := range warnings {
+		if traceWarning, ok := explained[warning.ExecutionPoint]; ok {
+			warnings[i] = traceWarning
+		}
+	}
+	return warnings
+}
+
+func AnalyzeFile(config Config, fset *token.FileSet, info *types.Info, file *ast.File) []Warning {
+	warnings, _ := AnalyzeFiles(config, fset, info, []*ast.File{file}, nil)
+	return warnings
+}
+
+// declaredFuncName returns the name under which calls of the declared function are resolved in the src.Scopes
+// Methods can be resolved only with type information
+func declaredFuncName(info *types.Info, funcDecl *ast.FuncDecl) (string, bool) {
+	if info != nil {
+		return src.FuncName(info.Defs[funcDecl.Name])
+	}
+	return funcDecl.Name.Name, funcDecl.Recv == nil
+}
+
+// AnalyzeFiles analyzes all functions of the package files
+// Specs of the declared functions are inferred before validation, so aliasing through the package helpers is found too
+// imported holds specs of the functions from the other packages and annotated functions (keyed by src.FuncName): they are not inferred
+// Inferred specs of the package functions are returned in the same form
+func AnalyzeFiles(
+	config Config,
+	fset *token.FileSet,
+	info *types.Info,
+	files []*ast.File,
+	imported map[string]src.FuncSpec,
+) ([]Warning, map[string]src.FuncSpec) {
+	var funcDecls []*ast.FuncDecl
+	var funcLits []*ast.FuncLit
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.FuncDecl:
+				if n.Body != nil {
+					funcDecls = append(funcDecls, n)
+				}
+				return false
+			case *ast.FuncLit: // function literals within function declarations are analyzed together with them
+				funcLits = append(funcLits, n)
+				return false
+			}
+			return true
+		})
+	}
+
+	funcs, knownSpecs, nextFuncId := config.Funcs(imported)
+	funcIds := make(map[*ast.FuncDecl]src.FuncId)
+	funcNames := make(map[*ast.FuncDecl]string)
+	for _, funcDecl := range funcDecls {
+		if name, ok := declaredFuncName(info, funcDecl); ok {
+			if _, ok := funcs[name]; ok { // user-defined or annotated spec is used as is
+				continue
+			}
+			funcIds[funcDecl], funcNames[funcDecl] = nextFuncId, name
+			funcs[name] = nextFuncId
+			nextFuncId++
+		}
+	}
+	scopes := func() src.Scopes {
+		scopes := config.Scopes(info)
+		scopes.Funcs = funcs
+		return scopes
+	}
+
+	// functions with unsupported constructs are neither inferred nor validated, so their calls are opaque
+	// specs of the functions untouched by the changes are still inferred, because the touched functions can call them
+	var warnings []Warning
+	executions := make([]src.Execution, len(funcDecls))
+	skipped := make([]bool, len(funcDecls))
+	inferred := make(map[src.FuncId]src.Execution)
+	for i, funcDecl := range funcDecls {
+		var err error
+		executions[i], err = src.ExecutionFromFunc(scopes(), fset, funcDecl)
+		if err != nil {
+			if config.Touched(fset, funcDecl) {
+				warnings = append(warnings, unsupportedWarnings(config, funcDecl, err)...)
+			}
+			skipped[i] = true
+			continue
+		}
+		if funcId, ok := funcIds[funcDecl]; ok {
+			inferred[funcId] = executions[i]
+		}
+	}
+	specs, inferenceErrs := src.InferFuncSpecsWithin(context.Background(), config.Limits, knownSpecs, inferred)
+	exported := make(map[string]src.FuncSpec)
+	for funcDecl, funcId := range funcIds {
+		if spec, ok := specs[funcId]; ok {
+			exported[funcNames[funcDecl]] = spec
+		}
+	}
+
+	for i, funcDecl := range funcDecls {
+		if skipped[i] || !config.Touched(fset, funcDecl) {
+			continue
+		}
+		funcWarnings := executionWarnings(config, specs, funcDecl, funcDecl, executions[i])
+		// failed inference leaves the callers without spec, so it is reported unless validation was cut short as well
+		funcId, ok := funcIds[funcDecl]
+		if err, failed := inferenceErrs[funcId]; ok && failed && !slices.ContainsFunc(funcWarnings, func(w Warning) bool { return w.Incomplete != nil }) {
+			rootPos := executions[i].SourceCodeReferences.References[executions[i].RootPoint]
+			funcWarnings = append(funcWarnings, Warning{Pos: rootPos, FuncDecl: funcDecl, Execution: executions[i], Incomplete: fmt.Errorf("spec inference: %w", err)})
+		}
+		warnings = append(warnings, funcWarnings...)
+	}
+	for _, funcLit := range funcLits {
+		if !config.Touched(fset, funcLit) {
+			continue
+		}
+		execution, err := src.ExecutionFromFuncLit(scopes(), fset, funcLit)
+		if err != nil {
+			warnings = append(warnings, unsupportedWarnings(config, nil, err)...)
+			continue
+		}
+		warnings = append(warnings, executionWarnings(config, specs, nil, funcLit, execution)...)
+	}
+	return warnings, exported
+}
+
+// isStdPackage reports whether package files are located in GOROOT
+// Analyzer is run for all dependencies in order to compute facts, but standard library never calls module functions and its analysis is too expensive (e.g. for runtime)
+func isStdPackage(pass *analysis.Pass) bool {
+	if len(pass.Files) == 0 || build.Default.GOROOT == "" {
+		return false
+	}
+	fileName := pass.Fset.File(pass.Files[0].Pos()).Name()
+	relativePath, err := filepath.Rel(filepath.Join(build.Default.GOROOT, "src"), fileName)
+	return err == nil && !strings.HasPrefix(relativePath, "..")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if isStdPackage(pass) {
+		return nil, nil
+	}
+	if err := analyzerConfig.CheckFuncSpecs(pass.TypesInfo); err != nil {
+		return nil, err
+	}
+	annotations, errs := FuncSpecAnnotations(pass.TypesInfo, pass.Files)
+	for _, err := range errs {
+		pass.Reportf(err.Pos, "%v", err)
+	}
+	imported := importFuncSpecFacts(pass)
+	maps.Copy(imported, annotations)
+	warnings, specs := AnalyzeFiles(analyzerConfig, pass.Fset, pass.TypesInfo, pass.Files, imported)
+	maps.Copy(specs, annotations)
+	exportFuncSpecFacts(pass, specs)
+	warnings, unused := analyzerConfig.Suppress(pass.Fset, pass.Files, warnings)
+	warnings, unused = analyzerConfig.KeepChanged(pass.Fset, warnings, unused)
+	for _, warning := range warnings {
+		if warning.Incomplete != nil {
+			pass.Report(analysis.Diagnostic{
+				Pos:      warning.Pos,
+				Category: incompleteCategory,
+				Message:  fmt.Sprintf("%v: %v", incompleteMessage, warning.Incomplete),
+			})
+			continue
+		}
+		pass.Report(analysis.Diagnostic{Pos: warning.Pos, Message: warningMessage})
+	}
+	for _, suppression := range unused {
+		pass.Report(analysis.Diagnostic{Pos: suppression.Pos, Message: unusedSuppressionMessage})
+	}
+	return nil, nil
+}
@@ -0,0 +1,243 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/sivukhin/gomakus/src"
+	"github.com/sivukhin/gomakus/utils"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
+
+func TestAnalyzerNoReturnFlag(t *testing.T) {
+	require.NoError(t, Analyzer.Flags.Set("no-return", "noreturn.die"))
+	t.Cleanup(func() { analyzerConfig = Config{} })
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "noreturn")
+}
+
+func TestAnalyzerFacts(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "usejoin")
+}
+
+func TestAnalyzerSpecsFlag(t *testing.T) {
+	require.NoError(t, Analyzer.Flags.Set("specs", "testdata/specs.json"))
+	t.Cleanup(func() { analyzerConfig = Config{} })
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "usepool")
+}
+
+func TestLoadFuncSpecsInvalid(t *testing.T) {
+	_, err := LoadFuncSpecs("testdata/specs_invalid.json")
+	require.ErrorContains(t, err, "invalid spec of func pool.Grow")
+}
+
+func TestCheckFuncSpecs(t *testing.T) {
+	_, _, info := utils.MustGenTypedSrc(`package main
+type Pool struct{}
+func (p *Pool) Grow(buf []byte) ([]byte, error) { return buf, nil }
+func f(p *Pool, x []byte) { a, err := p.Grow(x); _, _ = a, err }`)
+	config := Config{FuncSpecs: map[string]src.FuncSpec{"(*main.Pool).Grow": src.MustParseFuncSpec("(p, buf) -> (next(buf), fresh)")}}
+	require.NoError(t, config.CheckFuncSpecs(info))
+
+	config.FuncSpecs["(*main.Pool).Grow"] = src.MustParseFuncSpec("(buf) -> next(buf)")
+	err := config.CheckFuncSpecs(info)
+	require.ErrorContains(t, err, "invalid spec of func (*main.Pool).Grow")
+	require.ErrorContains(t, err, "spec (p0) -> next(p0) has 1 inputs and 1 outputs, but function has 2 inputs and 2 outputs")
+}
+
+func TestAnalyzerAnnotations(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "annotated")
+}
+
+func TestFuncSpecAnnotationsInvalid(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+//gomakus:spec (buf) -> next(s)
+func reset(buf []byte) []byte { return buf[:0] }`)
+	specs, errs := FuncSpecAnnotations(info, []*ast.File{file})
+	require.Empty(t, specs)
+	require.Len(t, errs, 1)
+	require.Equal(t, 2, fset.Position(errs[0].Pos).Line)
+	require.ErrorContains(t, errs[0], "unknown input s")
+
+	fset, file, info = utils.MustGenTypedSrc(`package main
+//gomakus:spec (buf) -> next(buf)
+func Grow(buf []byte) ([]byte, error) { return buf, nil }
+type Pool interface {
+	//gomakus:spec (buf) -> next(buf)
+	Grow(buf []byte) []byte
+}`)
+	specs, errs = FuncSpecAnnotations(info, []*ast.File{file})
+	require.Empty(t, specs)
+	require.Len(t, errs, 2)
+	require.Equal(t, 2, fset.Position(errs[0].Pos).Line)
+	require.EqualError(t, errs[0], "invalid gomakus:spec directive: spec (p0) -> next(p0) has 1 inputs and 1 outputs, but function has 1 inputs and 2 outputs")
+	require.Equal(t, 5, fset.Position(errs[1].Pos).Line)
+	require.ErrorContains(t, errs[1], "but function has 2 inputs and 1 outputs")
+}
+
+func TestAnalyzerSuppressions(t *testing.T) {
+	require.NoError(t, Analyzer.Flags.Set("report-unused-suppressions", "true"))
+	t.Cleanup(func() { analyzerConfig = Config{} })
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "suppressed")
+}
+
+func TestAnalyzerStdFuncSpecs(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "stdspecs")
+}
+
+func TestAnalyzerLimits(t *testing.T) {
+	require.NoError(t, Analyzer.Flags.Set("max-points", "30"))
+	t.Cleanup(func() { analyzerConfig = Config{} })
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "incomplete")
+}
+
+func TestAnalyzeFilesUnsupported(t *testing.T) {
+	fset, file := utils.MustGenSrc(`package main
+func unsupported(x []int) {
+	x
+}
+func supported(prefix []int) ([]int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2)
+	return a, b
+}`)
+	warnings, _ := AnalyzeFiles(Config{}, fset, nil, []*ast.File{file}, nil)
+	require.Len(t, warnings, 1)
+	require.Equal(t, "supported", warnings[0].FuncName())
+	require.Nil(t, warnings[0].Incomplete)
+
+	warnings, _ = AnalyzeFiles(Config{ReportUnsupported: true}, fset, nil, []*ast.File{file}, nil)
+	require.Len(t, warnings, 2)
+	require.Equal(t, "unsupported", warnings[0].FuncName())
+	require.Equal(t, 3, fset.Position(warnings[0].Pos).Line)
+	require.ErrorContains(t, warnings[0].Incomplete, "unsupported construct")
+}
+
+func TestAnalyzeFilesExplain(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+type Stack struct{ items []int }
+func (s Stack) Push(v int) Stack { s.items = append(s.items, v); return s }
+func f(prefix []int, n int) ([]int, []int) {
+	a := prefix
+	if n > 0 {
+		a = append(a, 1)
+	}
+	b := append(prefix, 2)
+	return a, b
+}
+func g(s Stack) (Stack, Stack) {
+	return s.Push(1), s.Push(2)
+}`)
+	warnings, _ := AnalyzeFiles(Config{Explain: true}, fset, info, []*ast.File{file}, nil)
+	require.Len(t, warnings, 2)
+	lines := func(positions ...token.Pos) []int {
+		var lines []int
+		for _, pos := range positions {
+			lines = append(lines, fset.Position(pos).Line)
+		}
+		return lines
+	}
+
+	require.Equal(t, "f", warnings[0].FuncName())
+	require.Equal(t, "prefix", warnings[0].Variable)
+	conflict, ok := warnings[0].ConflictPos()
+	require.True(t, ok)
+	require.Equal(t, []int{7}, lines(conflict))
+	require.Equal(t, []int{4, 5, 7, 8, 9}, slices.Compact(lines(warnings[0].TracePositions()...)))
+
+	require.Equal(t, "g", warnings[1].FuncName())
+	require.Equal(t, "s", warnings[1].Variable)
+
+	warnings, _ = AnalyzeFiles(Config{}, fset, info, []*ast.File{file}, nil)
+	require.Len(t, warnings, 2)
+	require.Equal(t, "prefix", warnings[0].Variable)
+	require.Empty(t, warnings[0].TracePositions())
+	_, ok = warnings[0].ConflictPos()
+	require.False(t, ok)
+}
+
+func TestParseUnifiedDiff(t *testing.T) {
+	changed, err := ParseUnifiedDiff(strings.NewReader(`diff --git a/a.go b/a.go
+index 1111111..2222222 100644
+--- a/a.go
++++ b/a.go
+@@ -3,0 +4,2 @@ func f() {
++	x := 1
++	y := 2
+@@ -10,2 +12 @@ func g() {
+-	a := 1
+-	b := 2
++	c := 3
+@@ -20,2 +21,0 @@ func h() {
+-	d := 4
+-	e := 5
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package pkg
+-func old() {}
+--- a/dir/b.go	2024-01-01 00:00:00
++++ b/dir/b.go	2024-01-01 00:00:00
+@@ -5,4 +5,3 @@
+ 	a := 1
+-	b := 2
+ 	c := 3
++	d := 4
+\ No newline at end of file
+`), "/root")
+	require.NoError(t, err)
+	require.Equal(t, ChangedLines{
+		"/root/a.go":     {{From: 4, To: 5}, {From: 12, To: 12}, {From: 21, To: 22}},
+		"/root/dir/b.go": {{From: 5, To: 7}},
+	}, changed)
+
+	_, err = ParseUnifiedDiff(strings.NewReader("+++ b/a.go\n@@ -a +b @@\n"), "/root")
+	require.ErrorContains(t, err, "malformed hunk header at line 2")
+}
+
+func TestAnalyzeFilesChanged(t *testing.T) {
+	fset, file := utils.MustGenSrc(`package main
+func grow(buf []int) []int { return append(buf, 1) }
+func f(prefix []int) ([]int, []int) {
+	a := grow(prefix)
+	b := grow(prefix)
+	return a, b
+}
+func g(prefix []int) ([]int, []int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2)
+	c := append(prefix, 3)
+	return a, b, c
+}
+func h(prefix []int) ([]int, []int) {
+	//gomakus:ignore
+	return append(prefix, 1), nil
+}`)
+	// untouched grow isn't validated, but its spec is inferred, so aliasing through it is found in f
+	config := Config{Changed: ChangedLines{"": {{From: 5, To: 5}, {From: 10, To: 10}}}}
+	warnings, _ := AnalyzeFiles(config, fset, nil, []*ast.File{file}, nil)
+	require.Len(t, warnings, 3)
+	require.Equal(t, "f", warnings[0].FuncName())
+	require.Equal(t, "g", warnings[1].FuncName())
+
+	// the warning outside of the changed lines and the unused suppression in untouched h are dropped
+	config.ReportUnusedSuppressions = true
+	warnings, unused := config.Suppress(fset, []*ast.File{file}, warnings)
+	require.Len(t, warnings, 3)
+	require.Len(t, unused, 1)
+	warnings, unused = config.KeepChanged(fset, warnings, unused)
+	require.Len(t, warnings, 2)
+	require.Equal(t, 5, fset.Position(warnings[0].Pos).Line)
+	require.Equal(t, 10, fset.Position(warnings[1].Pos).Line)
+	require.Empty(t, unused)
+}
@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/sivukhin/gomakus/src"
+)
+
+// specDirective declares spec of the function in the textual notation of src.ParseFuncSpec right in its doc comment:
+//
+//	//gomakus:spec (buf, _) -> next(buf)
+//	func Grow(buf []byte, n int) []byte
+//
+// Directive can be attached to the interface methods too, so all calls through the interface use it
+// Specs of the methods describe receiver as the first argument: //gomakus:spec (b, buf) -> next(buf)
+const specDirective = "//gomakus:spec "
+
+const invalidSpecMessage = "invalid gomakus:spec directive"
+
+// AnnotationError describes invalid spec directive
+type AnnotationError struct {
+	Pos token.Pos
+	Err error
+}
+
+func (e AnnotationError) Error() string { return fmt.Sprintf("%v: %v", invalidSpecMessage, e.Err) }
+
+// FuncSpecAnnotations collects specs declared by directives on the function declarations and interface methods (keyed by src.FuncName)
+// Interface methods can be resolved only with type information
+func FuncSpecAnnotations(info *types.Info, files []*ast.File) (map[string]src.FuncSpec, []AnnotationError) {
+	specs := make(map[string]src.FuncSpec)
+	var errs []AnnotationError
+	// signature is nil without type information, so arity of the specs isn't checked then
+	collect := func(doc *ast.CommentGroup, name string, ok bool, signature *types.Signature) {
+		if doc == nil || !ok {
+			return
+		}
+		for _, comment := range doc.List {
+			text, isDirective := strings.CutPrefix(comment.Text, specDirective)
+			if !isDirective {
+				continue
+			}
+			_, spec, err := src.ParseFuncSpec(text)
+			if err != nil {
+				errs = append(errs, AnnotationError{Pos: comment.Pos(), Err: err})
+				continue
+			}
+			if signature != nil {
+				if err := checkSpecSignature(spec, signature); err != nil {
+					errs = append(errs, AnnotationError{Pos: comment.Pos(), Err: err})
+					continue
+				}
+			}
+			specs[name] = spec
+		}
+	}
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case *ast.FuncDecl:
+				name, ok := declaredFuncName(info, n)
+				var signature *types.Signature
+				if info != nil {
+					signature = funcSignature(info.Defs[n.Name])
+				}
+				collect(n.Doc, name, ok, signature)
+			case *ast.InterfaceType:
+				if info == nil {
+					return false
+				}
+				for _, method := range n.Methods.List {
+					if len(method.Names) == 1 {
+						obj := info.Defs[method.Names[0]]
+						name, ok := src.FuncName(obj)
+						collect(method.Doc, name, ok, funcSignature(obj))
+					}
+				}
+			}
+			return true
+		})
+	}
+	return specs, errs
+}
+
+// funcSignature returns signature of the function object or nil for other objects
+func funcSignature(obj types.Object) *types.Signature {
+	if funcObj, ok := obj.(*types.Func); ok {
+		return funcObj.Type().(*types.Signature)
+	}
+	return nil
+}
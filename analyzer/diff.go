@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LineRange is the range of lines [From, To] (both inclusive, starting from 1)
+type LineRange struct {
+	From, To int
+}
+
+// ChangedLines maps absolute file paths to the sorted ranges of the lines added or modified by the diff
+// Lines around the pure deletions are considered changed too, because deletion can change the behaviour of the code around it
+type ChangedLines map[string][]LineRange
+
+func (c ChangedLines) add(fileName string, from, to int) {
+	ranges := c[fileName]
+	if n := len(ranges); n > 0 && ranges[n-1].To+1 >= from {
+		ranges[n-1].To = max(ranges[n-1].To, to)
+		return
+	}
+	c[fileName] = append(ranges, LineRange{From: from, To: to})
+}
+
+// AddFile marks all lines of the file as changed (e.g. the new file which isn't tracked by the diff)
+func (c ChangedLines) AddFile(fileName string) {
+	c[fileName] = []LineRange{{From: 1, To: math.MaxInt}}
+}
+
+// Overlaps reports whether any line from the range [from, to] of the file is changed
+func (c ChangedLines) Overlaps(fileName string, from, to int) bool {
+	for _, lineRange := range c[fileName] {
+		if lineRange.From <= to && from <= lineRange.To {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether the line of the position is changed
+func (c ChangedLines) Contains(position token.Position) bool {
+	return c.Overlaps(position.Filename, position.Line, position.Line)
+}
+
+// parseHunkRange parses "start[,count]" part of the hunk header
+func parseHunkRange(value string) (int, int, error) {
+	startValue, countValue, ok := strings.Cut(value, ",")
+	start, err := strconv.Atoi(startValue)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		return start, 1, nil
+	}
+	count, err := strconv.Atoi(countValue)
+	return start, count, err
+}
+
+// diffFileName extracts path of the new file from the "+++ b/path[\ttimestamp]" line (empty for the deleted files)
+func diffFileName(value string) string {
+	value, _, _ = strings.Cut(value, "\t")
+	if value == "/dev/null" {
+		return ""
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+	if path, ok := strings.CutPrefix(value, "b/"); ok {
+		return path
+	}
+	return value
+}
+
+// ParseUnifiedDiff collects changed lines of the new files from the unified diff (with or without context lines)
+// Relative paths of the diff are resolved against the root directory
+func ParseUnifiedDiff(r io.Reader, root string) (ChangedLines, error) {
+	changed := make(ChangedLines)
+	var fileName string
+	// line is the number of the next line of the new file in the hunk, oldLeft and newLeft are the numbers of the hunk lines yet to be read
+	line, oldLeft, newLeft := 0, 0, 0
+	// deleted is set if lines were deleted after the last unchanged or added line
+	deleted := false
+	flushDeletion := func() {
+		if deleted && fileName != "" {
+			changed.add(fileName, max(line-1, 1), line)
+		}
+		deleted = false
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		text := scanner.Text()
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(text, `\`): // "\ No newline at end of file" marker
+			case strings.HasPrefix(text, "-"):
+				oldLeft--
+				deleted = true
+			case strings.HasPrefix(text, "+"):
+				if fileName != "" {
+					changed.add(fileName, line, line)
+				}
+				newLeft--
+				line++
+				deleted = false
+			default: // context line (its leading space can be stripped by the editors, so it's not checked)
+				flushDeletion()
+				oldLeft--
+				newLeft--
+				line++
+			}
+			if oldLeft <= 0 && newLeft <= 0 {
+				flushDeletion()
+			}
+			continue
+		}
+		if value, ok := strings.CutPrefix(text, "+++ "); ok {
+			fileName = diffFileName(value)
+			if fileName != "" && !filepath.IsAbs(fileName) {
+				fileName = filepath.Join(root, filepath.FromSlash(fileName))
+			}
+			continue
+		}
+		if !strings.HasPrefix(text, "@@ ") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+			return nil, fmt.Errorf("malformed hunk header at line %v: %v", lineNumber, text)
+		}
+		_, oldCount, err := parseHunkRange(fields[1][1:])
+		if err != nil {
+			return nil, fmt.Errorf("malformed hunk header at line %v: %w", lineNumber, err)
+		}
+		newStart, newCount, err := parseHunkRange(fields[2][1:])
+		if err != nil {
+			return nil, fmt.Errorf("malformed hunk header at line %v: %w", lineNumber, err)
+		}
+		line, oldLeft, newLeft = newStart, oldCount, newCount
+		if newCount == 0 { // start of the empty range points to the line preceding the deletion
+			line++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read diff: %w", err)
+	}
+	return changed, nil
+}
+
+// Touched reports whether the function (together with its doc comment) overlaps with Config.Changed lines (always true if Changed isn't set)
+func (c Config) Touched(fset *token.FileSet, node ast.Node) bool {
+	if c.Changed == nil {
+		return true
+	}
+	from := node.Pos()
+	if funcDecl, ok := node.(*ast.FuncDecl); ok && funcDecl.Doc != nil {
+		from = funcDecl.Doc.Pos()
+	}
+	fromPosition, toPosition := fset.Position(from), fset.Position(node.End())
+	return c.Changed.Overlaps(fromPosition.Filename, fromPosition.Line, toPosition.Line)
+}
+
+// changed reports whether the position is located on the Config.Changed lines (always true if Changed isn't set)
+func (c Config) changed(fset *token.FileSet, pos token.Pos) bool {
+	return c.Changed == nil || c.Changed.Contains(fset.Position(pos))
+}
+
+// KeepChanged drops warnings and unused suppressions located outside of the Config.Changed lines (both are returned as is if Changed isn't set)
+// Incomplete analysis is kept for the whole touched function even if its header is unchanged
+func (c Config) KeepChanged(fset *token.FileSet, warnings []Warning, unused []Suppression) ([]Warning, []Suppression) {
+	if c.Changed == nil {
+		return warnings, unused
+	}
+	var keptWarnings []Warning
+	for _, warning := range warnings {
+		if warning.Incomplete != nil || c.changed(fset, warning.Pos) {
+			keptWarnings = append(keptWarnings, warning)
+		}
+	}
+	var keptUnused []Suppression
+	for _, suppression := range unused {
+		if c.changed(fset, suppression.Pos) {
+			keptUnused = append(keptUnused, suppression)
+		}
+	}
+	return keptWarnings, keptUnused
+}
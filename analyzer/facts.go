@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/sivukhin/gomakus/src"
+)
+
+// funcSpecFact carries spec inferred for the exported function to the packages which import it
+type funcSpecFact struct {
+	Spec src.FuncSpec
+}
+
+func (*funcSpecFact) AFact() {}
+
+func (f *funcSpecFact) String() string { return fmt.Sprintf("spec%v", f.Spec) }
+
+// importFuncSpecFacts collects specs of the functions from all dependencies of the package (keyed by src.FuncName)
+func importFuncSpecFacts(pass *analysis.Pass) map[string]src.FuncSpec {
+	specs := make(map[string]src.FuncSpec)
+	for _, fact := range pass.AllObjectFacts() {
+		spec, ok := fact.Fact.(*funcSpecFact)
+		if !ok {
+			continue
+		}
+		if name, ok := src.FuncName(fact.Object); ok {
+			specs[name] = spec.Spec
+		}
+	}
+	return specs
+}
+
+// exportFuncSpecFacts attaches inferred specs to the exported functions and methods of the package
+// Unexported functions can't be called from other packages, so their specs are kept local
+func exportFuncSpecFacts(pass *analysis.Pass, specs map[string]src.FuncSpec) {
+	for _, obj := range pass.TypesInfo.Defs {
+		funcObj, ok := obj.(*types.Func)
+		if !ok || !funcObj.Exported() {
+			continue
+		}
+		name, ok := src.FuncName(funcObj)
+		if !ok {
+			continue
+		}
+		if spec, ok := specs[name]; ok {
+			pass.ExportObjectFact(funcObj, &funcSpecFact{Spec: spec})
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/sivukhin/gomakus/src"
+)
+
+// specsFileFunc declares spec of single function in the specs file:
+//
+//	{
+//	  "func": "github.com/acme/pool.Grow",
+//	  "inputs": [[""], ["_"]],
+//	  "outputs": [[{"arg": 0, "gen": "next"}]]
+//	}
+//
+// Func is a name in the src.FuncName form (e.g. github.com/acme/pool.Grow or (*github.com/acme/pool.Builder).Bytes)
+// Inputs lists components of every argument as dot-separated field paths: "" stands for the whole argument ("_" can be used for the argument which is not referenced by the outputs)
+// Outputs lists components of every result; component without arg is a fresh value
+// Alternatively, spec can be written in the textual notation of src.ParseFuncSpec: {"func": "github.com/acme/pool.Grow", "spec": "(buf, _) -> next(buf)"}
+type specsFileFunc struct {
+	Func    string                 `json:"func"`
+	Spec    string                 `json:"spec"`
+	Inputs  [][]string             `json:"inputs"`
+	Outputs [][]specsFileOutputRef `json:"outputs"`
+}
+
+type specsFileOutputRef struct {
+	Arg   *int   `json:"arg,omitempty"`
+	Field string `json:"field,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Gen   string `json:"gen,omitempty"`
+}
+
+var specsFileGens = map[string]src.GenChangeType{
+	"":     src.SameGen,
+	"same": src.SameGen,
+	"prev": src.PrevGen,
+	"next": src.NextGen,
+}
+
+// LoadFuncSpecs reads specs of the functions (keyed by src.FuncName) from the JSON file with the list of specsFileFunc
+func LoadFuncSpecs(path string) (map[string]src.FuncSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read specs file: %w", err)
+	}
+	var funcs []specsFileFunc
+	if err := json.Unmarshal(data, &funcs); err != nil {
+		return nil, fmt.Errorf("unable to parse specs file %v: %w", path, err)
+	}
+	specs := make(map[string]src.FuncSpec, len(funcs))
+	for _, f := range funcs {
+		if f.Func == "" {
+			return nil, fmt.Errorf("func name is missing in specs file %v", path)
+		}
+		if _, ok := specs[f.Func]; ok {
+			return nil, fmt.Errorf("func %v is declared twice in specs file %v", f.Func, path)
+		}
+		spec, err := f.funcSpec()
+		if err != nil {
+			return nil, fmt.Errorf("invalid spec of func %v in specs file %v: %w", f.Func, path, err)
+		}
+		specs[f.Func] = spec
+	}
+	return specs, nil
+}
+
+func (f specsFileFunc) funcSpec() (spec src.FuncSpec, err error) {
+	if f.Spec != "" {
+		if f.Inputs != nil || f.Outputs != nil {
+			return src.FuncSpec{}, fmt.Errorf("spec can't be combined with inputs and outputs")
+		}
+		_, spec, err := src.ParseFuncSpec(f.Spec)
+		return spec, err
+	}
+	inputs := make(src.FuncMultiInput, len(f.Inputs))
+	for i, input := range f.Inputs {
+		inputs[i] = src.FuncSingleInput{}
+		for _, field := range input {
+			if field == "_" {
+				field = ""
+			}
+			inputs[i] = append(inputs[i], src.VarSelector{VarId: src.VarId(i), Selector: specsFilePath(field)})
+		}
+	}
+	outputs := make(src.FuncMultiOutput, len(f.Outputs))
+	for i, output := range f.Outputs {
+		outputs[i] = src.FuncSingleOutput{}
+		for _, ref := range output {
+			gen, ok := specsFileGens[ref.Gen]
+			if !ok {
+				return src.FuncSpec{}, fmt.Errorf("unknown gen %q (expected same, prev or next)", ref.Gen)
+			}
+			inputRef := src.FuncInputRef{ArgIndex: src.BlankVarId}
+			if ref.Arg != nil {
+				inputRef = src.FuncInputRef{ArgIndex: *ref.Arg, SelectorIndex: -1}
+				if 0 <= *ref.Arg && *ref.Arg < len(f.Inputs) {
+					for j, field := range f.Inputs[*ref.Arg] {
+						if field == ref.Field {
+							inputRef.SelectorIndex = j
+						}
+					}
+				}
+			}
+			outputs[i] = append(outputs[i], src.FuncOutputRef{InputRef: inputRef, OutputPath: specsFilePath(ref.Path), GenChange: gen})
+		}
+	}
+	// NewFuncSpec asserts consistency of the spec, so its panic is converted to the error here
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return src.NewFuncSpec(inputs, outputs), nil
+}
+
+func specsFilePath(path string) src.Path {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// checkSpecSignature reports mismatch of the numbers of inputs (receiver included) and outputs of the spec and of the function signature
+func checkSpecSignature(spec src.FuncSpec, signature *types.Signature) error {
+	inputs := signature.Params().Len()
+	if signature.Recv() != nil {
+		inputs++
+	}
+	if len(spec.Inputs) != inputs || len(spec.Outputs) != signature.Results().Len() {
+		return fmt.Errorf(
+			"spec %v has %v inputs and %v outputs, but function has %v inputs and %v outputs",
+			spec, len(spec.Inputs), len(spec.Outputs), inputs, signature.Results().Len(),
+		)
+	}
+	return nil
+}
+
+// CheckFuncSpecs verifies that user-defined specs match signatures of the functions declared or used in the package
+// Names of the specs files are resolved to the functions only with type information, so mismatches can't be found by LoadFuncSpecs
+func (c Config) CheckFuncSpecs(info *types.Info) error {
+	if info == nil || len(c.FuncSpecs) == 0 {
+		return nil
+	}
+	mismatches := make(map[string]error)
+	for _, objects := range []map[*ast.Ident]types.Object{info.Defs, info.Uses} {
+		for _, obj := range objects {
+			funcObj, ok := obj.(*types.Func)
+			if !ok {
+				continue
+			}
+			name, ok := src.FuncName(funcObj)
+			if !ok {
+				continue
+			}
+			spec, ok := c.FuncSpecs[name]
+			if !ok {
+				continue
+			}
+			if err := checkSpecSignature(spec, funcObj.Type().(*types.Signature)); err != nil {
+				mismatches[name] = fmt.Errorf("invalid spec of func %v: %w", name, err)
+			}
+		}
+	}
+	names := make([]string, 0, len(mismatches))
+	for name := range mismatches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	errs := make([]error, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, mismatches[name])
+	}
+	return errors.Join(errs...)
+}
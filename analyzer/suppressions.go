@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+)
+
+const (
+	ignoreDirective = "//gomakus:ignore"
+	nolintDirective = "//nolint"
+	nolintLinter    = "gomakus"
+	nolintAll       = "all"
+)
+
+// Suppression silences warnings found within [From, To) range of the file
+// Directive on the function declaration doc covers the whole declaration,
+// directive at the end of the line covers the line and directive on the separate line covers the next line too:
+//
+//	//gomakus:ignore prefix is never reused
+//	b := append(prefix, "b") //nolint:gomakus
+//
+// Generic is set for the directives which suppress all linters (bare //nolint and //nolint:all): they are never reported as unused, because they can target other linters
+type Suppression struct {
+	Pos     token.Pos
+	Reason  string
+	From    token.Pos
+	To      token.Pos
+	Generic bool
+}
+
+// parseSuppression recognizes //gomakus:ignore [reason], //nolint:gomakus[,other] [// reason] and generic //nolint[:all] [// reason] directives
+func parseSuppression(text string) (suppression Suppression, ok bool) {
+	if reason, ok := strings.CutPrefix(text, ignoreDirective); ok && (reason == "" || reason[0] == ' ') {
+		return Suppression{Reason: strings.TrimSpace(reason)}, true
+	}
+	directive, ok := strings.CutPrefix(text, nolintDirective)
+	if !ok || (directive != "" && directive[0] != ' ' && directive[0] != ':') {
+		return Suppression{}, false
+	}
+	linters, reason, _ := strings.Cut(directive, " ")
+	reason = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(reason), "//"))
+	if linters == "" {
+		return Suppression{Reason: reason, Generic: true}, true
+	}
+	names := strings.Split(strings.TrimPrefix(linters, ":"), ",")
+	if slices.Contains(names, nolintAll) {
+		return Suppression{Reason: reason, Generic: true}, true
+	}
+	if slices.Contains(names, nolintLinter) {
+		return Suppression{Reason: reason}, true
+	}
+	return Suppression{}, false
+}
+
+// Suppressions collects suppression directives from the comments of the files
+func Suppressions(fset *token.FileSet, files []*ast.File) []Suppression {
+	var suppressions []Suppression
+	for _, file := range files {
+		tokenFile := fset.File(file.Pos())
+		// lineCode holds minimal column of the code on the line, so trailing comments can be distinguished from the separate ones
+		lineCode := make(map[int]int)
+		docs := make(map[*ast.Comment]*ast.FuncDecl)
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch n := node.(type) {
+			case nil, *ast.File, *ast.CommentGroup, *ast.Comment:
+				return true
+			case *ast.FuncDecl:
+				if n.Doc != nil {
+					for _, comment := range n.Doc.List {
+						docs[comment] = n
+					}
+				}
+			}
+			position := fset.Position(node.Pos())
+			if column, ok := lineCode[position.Line]; !ok || position.Column < column {
+				lineCode[position.Line] = position.Column
+			}
+			return true
+		})
+		lineEnd := func(line int) token.Pos {
+			if line < tokenFile.LineCount() {
+				return tokenFile.LineStart(line + 1)
+			}
+			return token.Pos(tokenFile.Base() + tokenFile.Size())
+		}
+
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				suppression, ok := parseSuppression(comment.Text)
+				if !ok {
+					continue
+				}
+				suppression.Pos = comment.Pos()
+				if funcDecl, ok := docs[comment]; ok {
+					suppression.From, suppression.To = funcDecl.Pos(), funcDecl.End()
+				} else {
+					position := fset.Position(comment.Pos())
+					suppression.From, suppression.To = tokenFile.LineStart(position.Line), lineEnd(position.Line)
+					if column, ok := lineCode[position.Line]; !ok || column > position.Column {
+						suppression.To = lineEnd(position.Line + 1)
+					}
+				}
+				suppressions = append(suppressions, suppression)
+			}
+		}
+	}
+	return suppressions
+}
+
+// Suppress drops warnings covered by the suppressions of the files
+// Suppressions which don't cover any warning are returned if ReportUnusedSuppressions is set (except the generic ones)
+func (c Config) Suppress(fset *token.FileSet, files []*ast.File, warnings []Warning) ([]Warning, []Suppression) {
+	suppressions := Suppressions(fset, files)
+	used := make([]bool, len(suppressions))
+	var kept []Warning
+	for _, warning := range warnings {
+		suppressed := false
+		for i, suppression := range suppressions {
+			if suppression.From <= warning.Pos && warning.Pos < suppression.To {
+				suppressed, used[i] = true, true
+			}
+		}
+		if !suppressed {
+			kept = append(kept, warning)
+		}
+	}
+	if !c.ReportUnusedSuppressions {
+		return kept, nil
+	}
+	var unused []Suppression
+	for i, suppression := range suppressions {
+		if !used[i] && !suppression.Generic {
+			unused = append(unused, suppression)
+		}
+	}
+	return kept, unused
+}
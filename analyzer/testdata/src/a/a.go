@@ -0,0 +1,62 @@
+package a
+
+import "strings"
+
+func parseUnnestedKeyFieldSet(raw string, prefix []string) [][]string {
+	ret := [][]string{}
+	for _, s := range strings.Fields(raw) {
+		next := append(prefix[:], s) // want "potential append overwrite found"
+		ret = append(ret, next)
+	}
+	return ret
+}
+
+func appendTwice(prefix []string) ([]string, []string) {
+	a := append(prefix, "a")
+	b := append(prefix, "b") // want "potential append overwrite found"
+	return a, b
+}
+
+func appendSequence(prefix []string) []string {
+	prefix = append(prefix, "a")
+	prefix = append(prefix, "b")
+	return prefix
+}
+
+func appendInClosure(prefix []string) func() ([]string, []string) {
+	return func() ([]string, []string) {
+		a := append(prefix, "a")
+		b := append(prefix, "b") // want "potential append overwrite found"
+		return a, b
+	}
+}
+
+func appendInInlinedClosure(prefix []string) ([]string, []string) {
+	a := append(prefix, "a")
+	b := func(s []string) []string {
+		return append(s, "b") // want "potential append overwrite found"
+	}(prefix)
+	return a, b
+}
+
+var appendInPackageClosure = func(prefix []string) ([]string, []string) {
+	a := append(prefix, "a")
+	b := append(prefix, "b") // want "potential append overwrite found"
+	return a, b
+}
+
+type path struct{ parts []string }
+
+func (p *path) child(name string) []string {
+	next := append(p.parts, name)
+	p.parts = append(p.parts, "") // want "potential append overwrite found"
+	return next
+}
+
+func with(prefix []string, s string) []string { return append(prefix, s) }
+
+func appendThroughHelper(prefix []string) ([]string, []string) {
+	a := with(prefix, "a")
+	b := with(prefix, "b") // want "potential append overwrite found"
+	return a, b
+}
@@ -0,0 +1,27 @@
+package annotated
+
+type Builder interface {
+	//gomakus:spec (b, buf, _) -> next(buf)
+	AppendTo(buf []byte, v int) []byte // want AppendTo:`spec`
+}
+
+var grow = func(buf []byte, n int) []byte { return append(buf, make([]byte, n)...)[:len(buf)] }
+
+// Grow calls grow through the variable, so its spec can't be inferred without directive
+//
+//gomakus:spec (buf, _) -> next(buf)
+func Grow(buf []byte, n int) []byte { // want Grow:`spec\(p0, _\) -> next\(p0\)`
+	return grow(buf, n)
+}
+
+func growTwice(buf []byte) ([]byte, []byte) {
+	a := Grow(buf, 1)
+	b := Grow(buf, 2) // want "potential append overwrite found"
+	return a, b
+}
+
+func appendToTwice(b Builder, buf []byte) ([]byte, []byte) {
+	x := b.AppendTo(buf, 1)
+	y := b.AppendTo(buf, 2) // want "potential append overwrite found"
+	return x, y
+}
@@ -0,0 +1,55 @@
+package incomplete
+
+func small(prefix []string) ([]string, []string) {
+	a := append(prefix, "a")
+	b := append(prefix, "b") // want "potential append overwrite found"
+	return a, b
+}
+
+func large(prefix []string, n int) []string { // want `gomakus analysis of the function was cut short: analysis budget exceeded: \d+ execution points exceed limit of 30`
+	a := prefix
+	if n == 0 {
+		a = append(a, "0")
+	}
+	if n == 1 {
+		a = append(a, "1")
+	}
+	if n == 2 {
+		a = append(a, "2")
+	}
+	if n == 3 {
+		a = append(a, "3")
+	}
+	if n == 4 {
+		a = append(a, "4")
+	}
+	return a
+}
+
+//gomakus:ignore generated code
+func largeSuppressed(prefix []string, n int) []string {
+	a := prefix
+	if n == 0 {
+		a = append(a, "0")
+	}
+	if n == 1 {
+		a = append(a, "1")
+	}
+	if n == 2 {
+		a = append(a, "2")
+	}
+	if n == 3 {
+		a = append(a, "3")
+	}
+	if n == 4 {
+		a = append(a, "4")
+	}
+	return a
+}
+
+func useLarge(prefix []string) ([]string, []string) {
+	// spec of large isn't inferred within the limits, so its result is treated as fresh value
+	a := large(prefix, 0)
+	b := large(prefix, 1)
+	return a, b
+}
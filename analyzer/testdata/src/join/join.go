@@ -0,0 +1,9 @@
+package join
+
+func Join(prefix []string, s string) []string { // want Join:`spec`
+	return append(prefix, s)
+}
+
+func Clone(prefix []string) []string { // want Clone:`spec`
+	return append([]string{}, prefix...)
+}
@@ -0,0 +1,20 @@
+package noreturn
+
+func die(reason string) {
+	panic(reason)
+}
+
+func guarded(prefix []int) []int {
+	if len(prefix) > 10 {
+		_ = append(prefix, 1)
+		die("prefix is too long")
+	}
+	return append(prefix, 2)
+}
+
+func unguarded(prefix []int) []int {
+	if len(prefix) > 10 {
+		_ = append(prefix, 1)
+	}
+	return append(prefix, 2) // want "potential append overwrite found"
+}
@@ -0,0 +1,15 @@
+package pool
+
+var grow = func(buf []byte, n int) []byte { return append(buf, make([]byte, n)...)[:len(buf)] }
+
+// Grow calls grow through the variable, so its spec can't be inferred
+func Grow(buf []byte, n int) []byte {
+	return grow(buf, n)
+}
+
+var reset = func(buf []byte) []byte { return buf[:0] }
+
+// Reset calls reset through the variable, so its spec can't be inferred
+func Reset(buf []byte) []byte {
+	return reset(buf)
+}
@@ -0,0 +1,72 @@
+package stdspecs
+
+import (
+	"bytes"
+	"slices"
+	"strconv"
+)
+
+func cloneBeforeAppend(prefix []string) ([]string, []string) {
+	a := append(slices.Clone(prefix), "a")
+	b := append(slices.Clone(prefix), "b")
+	return a, b
+}
+
+func clipBeforeAppend(prefix []string) ([]string, []string) {
+	a := append(prefix, "a")
+	b := append(slices.Clip(prefix), "b")
+	return a, b
+}
+
+func bytesCloneBeforeAppend(prefix []byte) ([]byte, []byte) {
+	a := append(prefix, 'a')
+	b := append(bytes.Clone(prefix), 'b')
+	return a, b
+}
+
+func insertTwice(prefix []string) ([]string, []string) {
+	a := slices.Insert(prefix, 0, "a")
+	b := slices.Insert(prefix, 0, "b") // want "potential append overwrite found"
+	return a, b
+}
+
+func growThenAppendTwice(prefix []string) ([]string, []string) {
+	grown := slices.Grow(prefix, 2)
+	a := append(grown, "a")
+	b := append(grown, "b") // want "potential append overwrite found"
+	return a, b
+}
+
+func appendIntTwice(prefix []byte) ([]byte, []byte) {
+	a := strconv.AppendInt(prefix, 1, 10)
+	b := strconv.AppendInt(prefix, 2, 10) // want "potential append overwrite found"
+	return a, b
+}
+
+func trimThenAppend(prefix []byte) ([]byte, []byte) {
+	a := append(prefix, 'a')
+	b := append(bytes.TrimSpace(prefix), 'b') // want "potential append overwrite found"
+	return a, b
+}
+
+type names []string
+
+func appendThroughConversion(prefix []string) ([]string, names) {
+	a := append(prefix, "a")
+	b := append(names(prefix), "b") // want "potential append overwrite found"
+	return a, b
+}
+
+func appendToConvertedString(prefix string) ([]byte, []byte) {
+	a := append([]byte(prefix), 'a')
+	b := append([]byte(prefix), 'b')
+	return a, b
+}
+
+func appendToMade(n int) ([]int, []int) {
+	s := make([]int, 0, n)
+	a := append(s, 1)
+	s = make([]int, 0, n)
+	b := append(s, 2)
+	return a, b
+}
@@ -0,0 +1,56 @@
+package suppressed
+
+func trailing(prefix []string) ([]string, []string) {
+	a := append(prefix, "a")
+	b := append(prefix, "b") //gomakus:ignore prefix has no spare capacity
+	return a, b
+}
+
+func separate(prefix []string) ([]string, []string) {
+	a := append(prefix, "a")
+	//nolint:errcheck,gomakus // prefix has no spare capacity
+	b := append(prefix, "b")
+	return a, b
+}
+
+//gomakus:ignore
+func wholeFunc(prefix []string) ([]string, []string, []string) {
+	a := append(prefix, "a")
+	b := append(prefix, "b")
+	c := append(prefix, "c")
+	return a, b, c
+}
+
+func otherLinter(prefix []string) ([]string, []string) {
+	a := append(prefix, "a")
+	b := append(prefix, "b") //nolint:errcheck // want "potential append overwrite found"
+	return a, b
+}
+
+func notOnPreviousLine(prefix []string) ([]string, []string) {
+	a := append(prefix, "a") //gomakus:ignore // want "unused gomakus suppression directive"
+	b := append(prefix, "b") // want "potential append overwrite found"
+	return a, b
+}
+
+func unused(prefix []string) []string {
+	//gomakus:ignorable is not a directive
+	return append(prefix, "a") //nolint:gomakus // want "unused gomakus suppression directive"
+}
+
+func nolintAll(prefix []string) ([]string, []string, []string) {
+	a := append(prefix, "a")
+	b := append(prefix, "b") //nolint
+	c := append(prefix, "c") //nolint:all // prefix has no spare capacity
+	return a, b, c
+}
+
+func nolintUnknown(prefix []string) ([]string, []string) {
+	a := append(prefix, "a")
+	b := append(prefix, "b") //nolintall // want "potential append overwrite found"
+	return a, b
+}
+
+func genericUnused(prefix []string) []string {
+	return append(prefix, "a") //nolint:errcheck,all
+}
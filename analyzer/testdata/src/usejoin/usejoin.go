@@ -0,0 +1,15 @@
+package usejoin
+
+import "join"
+
+func joinTwice(prefix []string) ([]string, []string) {
+	a := join.Join(prefix, "a")
+	b := join.Join(prefix, "b") // want "potential append overwrite found"
+	return a, b
+}
+
+func joinClone(prefix []string) ([]string, []string) {
+	a := join.Join(join.Clone(prefix), "a")
+	b := join.Join(prefix, "b")
+	return a, b
+}
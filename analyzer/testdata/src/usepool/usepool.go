@@ -0,0 +1,19 @@
+package usepool
+
+import "pool"
+
+func growTwice(buf []byte) ([]byte, []byte) {
+	a := pool.Grow(buf, 1)
+	b := pool.Grow(buf, 2) // want "potential append overwrite found"
+	return a, b
+}
+
+func growOnce(buf []byte) []byte {
+	return pool.Grow(buf, 1)
+}
+
+func growAfterReset(buf []byte) ([]byte, []byte) {
+	a := pool.Grow(buf, 1)
+	b := pool.Grow(pool.Reset(a), 2) // want "potential append overwrite found"
+	return a, b
+}
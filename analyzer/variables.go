@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/sivukhin/gomakus/src"
+)
+
+// aliasedVariable returns source of the call argument which spare capacity is written at the warning point (e.g. prefix for append(prefix, 1))
+// Warning points always belong to the calls of the functions with NextGen outputs, so the argument is found by the spec of the called function
+func aliasedVariable(root ast.Node, specs src.FuncSpecCollection, execution src.Execution, point src.ExecutionPoint) string {
+	pos, ok := execution.SourceCodeReferences.References[point]
+	if !ok || root == nil {
+		return ""
+	}
+	argIndex := -1
+	var inputs int
+	for _, transitions := range execution.Transitions {
+		for _, transition := range transitions {
+			op, ok := transition.Operation.(src.UseSelectorsOp)
+			if !ok || transition.ToPoint != point {
+				continue
+			}
+			for _, outputs := range specs[op.FuncId].Outputs {
+				for _, output := range outputs {
+					if output.GenChange == src.NextGen && output.InputRef.ArgIndex != src.BlankVarId && argIndex == -1 {
+						argIndex, inputs = output.InputRef.ArgIndex, len(op.Inputs)
+					}
+				}
+			}
+		}
+	}
+	if argIndex == -1 {
+		return ""
+	}
+	var call *ast.CallExpr
+	ast.Inspect(root, func(node ast.Node) bool {
+		if expr, ok := node.(*ast.CallExpr); ok && expr.Pos() == pos && call == nil {
+			call = expr
+		}
+		return call == nil
+	})
+	if call == nil {
+		return ""
+	}
+	args := call.Args
+	// receiver of the method call goes first in the inputs of the operation
+	if selector, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok && inputs == len(call.Args)+1 {
+		args = append([]ast.Expr{selector.X}, call.Args...)
+	}
+	if argIndex >= len(args) {
+		return ""
+	}
+	return types.ExprString(args[argIndex])
+}
+
+// TracePositions returns source positions of the trace leading to the warning (it is set only in Config.Explain mode)
+// Trace starts at the function declaration and consecutive points of the same statement are merged
+func (w Warning) TracePositions() []token.Pos {
+	if len(w.ValidationWarning.Trace) == 0 {
+		return nil
+	}
+	references := w.Execution.SourceCodeReferences.References
+	points := []src.ExecutionPoint{w.Execution.RootPoint}
+	for _, transition := range w.ValidationWarning.Trace {
+		points = append(points, transition.ToPoint)
+	}
+	var positions []token.Pos
+	for _, point := range points {
+		pos, ok := references[point]
+		if !ok || len(positions) > 0 && positions[len(positions)-1] == pos {
+			continue
+		}
+		positions = append(positions, pos)
+	}
+	return positions
+}
+
+// ConflictPos returns position of the earlier append which result can be overwritten (it is known only in Config.Explain mode)
+func (w Warning) ConflictPos() (token.Pos, bool) {
+	if len(w.ValidationWarning.Trace) == 0 {
+		return token.NoPos, false
+	}
+	pos, ok := w.Execution.SourceCodeReferences.References[w.ValidationWarning.ConflictPoint]
+	return pos, ok
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+const (
+	// defaultBaselineFile is written by the `gomakus baseline write` command if -baseline flag is not set
+	defaultBaselineFile  = ".gomakus-baseline.json"
+	baselineVersion      = 1
+	fixedBaselineMessage = "baseline finding is fixed"
+)
+
+type (
+	baseline struct {
+		Version  int               `json:"version"`
+		Findings []baselineFinding `json:"findings"`
+	}
+	// baselineFinding is identified only by the Fingerprint, other fields are kept for the humans reading the baseline
+	baselineFinding struct {
+		Fingerprint string `json:"fingerprint"`
+		Rule        string `json:"rule"`
+		Package     string `json:"package"`
+		Func        string `json:"func,omitempty"`
+		Snippet     string `json:"snippet"`
+		Variable    string `json:"variable,omitempty"`
+		File        string `json:"file"`
+		Line        int    `json:"line"`
+	}
+)
+
+func readBaseline(path string) (baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return baseline{}, fmt.Errorf("unable to read baseline: %w", err)
+	}
+	var known baseline
+	if err := json.Unmarshal(content, &known); err != nil {
+		return baseline{}, fmt.Errorf("unable to parse baseline '%v': %w", path, err)
+	}
+	if known.Version != baselineVersion {
+		return baseline{}, fmt.Errorf("unsupported baseline '%v' version %v (expected %v)", path, known.Version, baselineVersion)
+	}
+	return known, nil
+}
+
+// baselineFingerprints computes fingerprints of the findings which are stable under unrelated changes of the code:
+// fingerprint depends only on the rule, package, enclosing function and source line of the finding with normalized whitespaces,
+// so findings survive line shifts and moves of the functions between files of the package
+type baselineFingerprints struct {
+	analysisPath string
+	pkg          *packages.Package
+	// sources caches lines of the files which findings were fingerprinted
+	sources map[string][]string
+}
+
+func newBaselineFingerprints(analysisPath string) baselineFingerprints {
+	return baselineFingerprints{analysisPath: analysisPath, sources: make(map[string][]string)}
+}
+
+// snippet returns source line at the position with all whitespace sequences replaced by the single space
+func (f *baselineFingerprints) snippet(position token.Position) string {
+	lines, ok := f.sources[position.Filename]
+	if !ok {
+		if content, err := os.ReadFile(position.Filename); err == nil {
+			lines = strings.Split(string(content), "\n")
+		}
+		f.sources[position.Filename] = lines
+	}
+	if position.Line < 1 || position.Line > len(lines) {
+		return ""
+	}
+	return strings.Join(strings.Fields(lines[position.Line-1]), " ")
+}
+
+// enclosingFunc returns name of the function declaration of the current package which contains the position
+func (f *baselineFingerprints) enclosingFunc(pos token.Pos) string {
+	for _, file := range f.pkg.Syntax {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if ok && funcDecl.Pos() <= pos && pos < funcDecl.End() {
+				return funcDeclName(funcDecl)
+			}
+		}
+	}
+	return ""
+}
+
+func (f *baselineFingerprints) finding(fset *token.FileSet, pos token.Pos, rule, funcName, variable string) baselineFinding {
+	position := fset.Position(pos)
+	fileName := position.Filename
+	if relativePath, ok := relativeFile(f.analysisPath, fileName); ok {
+		fileName = relativePath
+	}
+	finding := baselineFinding{
+		Rule:     rule,
+		Package:  f.pkg.PkgPath,
+		Func:     funcName,
+		Snippet:  f.snippet(position),
+		Variable: variable,
+		File:     fileName,
+		Line:     position.Line,
+	}
+	hash := sha256.Sum256([]byte(strings.Join([]string{finding.Rule, finding.Package, finding.Func, finding.Snippet}, "\x00")))
+	finding.Fingerprint = hex.EncodeToString(hash[:8])
+	return finding
+}
+
+func (f *baselineFingerprints) warning(fset *token.FileSet, warning analyzer.Warning) baselineFinding {
+	rule := appendOverwriteRule
+	if warning.Incomplete != nil {
+		rule = incompleteAnalysisRule
+	}
+	funcName := f.enclosingFunc(warning.Pos)
+	if warning.FuncDecl != nil {
+		funcName = funcDeclName(warning.FuncDecl)
+	}
+	return f.finding(fset, warning.Pos, rule, funcName, warning.Variable)
+}
+
+func (f *baselineFingerprints) unusedSuppression(fset *token.FileSet, suppression analyzer.Suppression) baselineFinding {
+	return f.finding(fset, suppression.Pos, unusedSuppressionRule, f.enclosingFunc(suppression.Pos), "")
+}
+
+// baselineWriter records all findings to the baseline file on Close (`gomakus baseline write` command)
+type baselineWriter struct {
+	baselineFingerprints
+	path     string
+	findings []baselineFinding
+}
+
+func newBaselineWriter(path string, analysisPath string) *baselineWriter {
+	return &baselineWriter{baselineFingerprints: newBaselineFingerprints(analysisPath), path: path, findings: make([]baselineFinding, 0)}
+}
+
+func (r *baselineWriter) Package(pkg *packages.Package) { r.pkg = pkg }
+
+func (r *baselineWriter) Warning(fset *token.FileSet, warning analyzer.Warning) {
+	r.findings = append(r.findings, r.warning(fset, warning))
+}
+
+func (r *baselineWriter) UnusedSuppression(fset *token.FileSet, suppression analyzer.Suppression) {
+	r.findings = append(r.findings, r.unusedSuppression(fset, suppression))
+}
+
+func (r *baselineWriter) Close() error {
+	// findings are sorted to keep diffs of the baseline file small
+	sort.Slice(r.findings, func(i, j int) bool {
+		a, b := r.findings[i], r.findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+	content, err := json.MarshalIndent(baseline{Version: baselineVersion, Findings: r.findings}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(r.path, append(content, '\n'), 0o644); err != nil {
+		return err
+	}
+	log.Printf("%v findings written to the baseline '%v'", len(r.findings), r.path)
+	return nil
+}
+
+// baselineFilter passes to the underlying reporter only findings missing in the baseline
+// Findings with the same fingerprint are matched by count, so new copy of the known finding within the same function is still reported
+type baselineFilter struct {
+	baselineFingerprints
+	reporter reporter
+	config   analyzer.Config
+	known    map[string][]baselineFinding
+	// analyzed holds functions validated under config.Changed restriction: known findings of other functions can't be fixed
+	analyzed map[baselineFunc]struct{}
+	// newFindings counts reported findings except incomplete analysis notices
+	newFindings int
+}
+
+type baselineFunc struct {
+	pkg      string
+	funcName string
+}
+
+func newBaselineFilter(reporter reporter, analysisPath string, config analyzer.Config, known baseline) *baselineFilter {
+	filter := &baselineFilter{
+		baselineFingerprints: newBaselineFingerprints(analysisPath),
+		reporter:             reporter,
+		config:               config,
+		known:                make(map[string][]baselineFinding),
+		analyzed:             make(map[baselineFunc]struct{}),
+	}
+	for _, finding := range known.Findings {
+		filter.known[finding.Fingerprint] = append(filter.known[finding.Fingerprint], finding)
+	}
+	return filter
+}
+
+// match removes the finding from the known ones and returns true if it was in the baseline
+func (r *baselineFilter) match(finding baselineFinding) bool {
+	known := r.known[finding.Fingerprint]
+	if len(known) == 0 {
+		return false
+	}
+	r.known[finding.Fingerprint] = known[1:]
+	return true
+}
+
+func (r *baselineFilter) Package(pkg *packages.Package) {
+	r.pkg = pkg
+	if r.config.Changed != nil {
+		for _, file := range pkg.Syntax {
+			for _, decl := range file.Decls {
+				if funcDecl, ok := decl.(*ast.FuncDecl); ok && r.config.Touched(pkg.Fset, funcDecl) {
+					r.analyzed[baselineFunc{pkg: pkg.PkgPath, funcName: funcDeclName(funcDecl)}] = struct{}{}
+				}
+			}
+		}
+	}
+	r.reporter.Package(pkg)
+}
+
+func (r *baselineFilter) Warning(fset *token.FileSet, warning analyzer.Warning) {
+	if r.match(r.warning(fset, warning)) {
+		return
+	}
+	if warning.Incomplete == nil {
+		r.newFindings++
+	}
+	r.reporter.Warning(fset, warning)
+}
+
+func (r *baselineFilter) UnusedSuppression(fset *token.FileSet, suppression analyzer.Suppression) {
+	if r.match(r.unusedSuppression(fset, suppression)) {
+		return
+	}
+	r.newFindings++
+	r.reporter.UnusedSuppression(fset, suppression)
+}
+
+// fixed returns baseline findings which weren't found during the analysis
+// If the analysis is restricted to the changed functions, findings of the other functions (and outside of any function) aren't fixed
+func (r *baselineFilter) fixed() []baselineFinding {
+	var fixed []baselineFinding
+	for _, known := range r.known {
+		for _, finding := range known {
+			if _, ok := r.analyzed[baselineFunc{pkg: finding.Package, funcName: finding.Func}]; ok || r.config.Changed == nil {
+				fixed = append(fixed, finding)
+			}
+		}
+	}
+	sort.Slice(fixed, func(i, j int) bool {
+		if fixed[i].File != fixed[j].File {
+			return fixed[i].File < fixed[j].File
+		}
+		return fixed[i].Line < fixed[j].Line
+	})
+	return fixed
+}
+
+// Close reports fixed findings to the log (so machine-readable reports stay valid) and closes the underlying reporter
+func (r *baselineFilter) Close() error {
+	fixed := r.fixed()
+	for _, finding := range fixed {
+		log.Printf("%v: rule=[%v], func=[%v], file=[%v], line=[%v]", fixedBaselineMessage, finding.Rule, finding.Func, finding.File, finding.Line)
+	}
+	if len(fixed) > 0 {
+		log.Printf("%v baseline findings are fixed, update the baseline with `gomakus baseline write`", len(fixed))
+	}
+	return r.reporter.Close()
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+// reportBaselineSource writes the source to the analysis path, analyzes it and passes all findings to the reporter
+func reportBaselineSource(t *testing.T, analysisPath string, config analyzer.Config, source string, report reporter) {
+	fileName := filepath.Join(analysisPath, "a.go")
+	require.NoError(t, os.WriteFile(fileName, []byte(source), 0o644))
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, fileName, nil, parser.ParseComments)
+	require.NoError(t, err)
+	config.ReportUnusedSuppressions = true
+	warnings, _ := analyzer.AnalyzeFiles(config, fset, nil, []*ast.File{file}, nil)
+	warnings, unused := config.Suppress(fset, []*ast.File{file}, warnings)
+	warnings, unused = config.KeepChanged(fset, warnings, unused)
+	report.Package(&packages.Package{PkgPath: "acme/pkg", Fset: fset, Syntax: []*ast.File{file}})
+	for _, warning := range warnings {
+		report.Warning(fset, warning)
+	}
+	for _, suppression := range unused {
+		report.UnusedSuppression(fset, suppression)
+	}
+	require.NoError(t, report.Close())
+}
+
+func TestBaseline(t *testing.T) {
+	analysisPath := t.TempDir()
+	baselinePath := filepath.Join(analysisPath, defaultBaselineFile)
+	reportBaselineSource(t, analysisPath, analyzer.Config{}, `package pkg
+func f(prefix []int) ([]int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2)
+	return a, b
+}
+//gomakus:ignore
+func g() {}
+func h(prefix []int) ([]int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2)
+	return a, b
+}`, newBaselineWriter(baselinePath, analysisPath))
+
+	known, err := readBaseline(baselinePath)
+	require.NoError(t, err)
+	require.Len(t, known.Findings, 3)
+	require.Equal(t, baselineFinding{
+		Fingerprint: known.Findings[0].Fingerprint,
+		Rule:        appendOverwriteRule,
+		Package:     "acme/pkg",
+		Func:        "f",
+		Snippet:     "b := append(prefix, 2)",
+		Variable:    "prefix",
+		File:        "a.go",
+		Line:        4,
+	}, known.Findings[0])
+	require.Equal(t, unusedSuppressionRule, known.Findings[1].Rule)
+	require.Equal(t, "h", known.Findings[2].Func)
+
+	// lines of f are shifted and reformatted, h is fixed and new finding appears in f
+	var buffer bytes.Buffer
+	filter := newBaselineFilter(newJsonReporter(&buffer, analysisPath), analysisPath, analyzer.Config{}, known)
+	reportBaselineSource(t, analysisPath, analyzer.Config{}, `package pkg
+
+import "fmt"
+
+func f(prefix []int) ([]int, []int, []int) {
+	fmt.Println(prefix)
+	a := append(prefix, 1)
+	b := append(prefix,   2)
+	c := append(prefix, 3)
+	return a, b, c
+}
+//gomakus:ignore
+func g() {}
+func h(prefix []int) ([]int, []int) {
+	return append(prefix, 1), nil
+}`, filter)
+
+	require.Equal(t, 1, filter.newFindings)
+	require.Contains(t, buffer.String(), `"line":9`)
+	require.Len(t, filter.fixed(), 1)
+	require.Equal(t, "h", filter.fixed()[0].Func)
+}
+
+func TestBaselineChanged(t *testing.T) {
+	analysisPath := t.TempDir()
+	baselinePath := filepath.Join(analysisPath, defaultBaselineFile)
+	reportBaselineSource(t, analysisPath, analyzer.Config{}, `package pkg
+func f(prefix []int) ([]int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2)
+	return a, b
+}
+func h(prefix []int) ([]int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2)
+	return a, b
+}`, newBaselineWriter(baselinePath, analysisPath))
+	known, err := readBaseline(baselinePath)
+	require.NoError(t, err)
+	require.Len(t, known.Findings, 2)
+
+	// both findings disappear, but only f is changed: h isn't analyzed, so its finding isn't fixed
+	source := `package pkg
+func f(prefix []int) ([]int, []int) {
+	return append(prefix, 1), nil
+}
+func h(prefix []int) ([]int, []int) {
+	return append(prefix, 1), nil
+}`
+	fileName := filepath.Join(analysisPath, "a.go")
+	config := analyzer.Config{Changed: analyzer.ChangedLines{fileName: {{From: 3, To: 3}}}}
+	filter := newBaselineFilter(newJsonReporter(&bytes.Buffer{}, analysisPath), analysisPath, config, known)
+	reportBaselineSource(t, analysisPath, config, source, filter)
+	require.Equal(t, 0, filter.newFindings)
+	require.Len(t, filter.fixed(), 1)
+	require.Equal(t, "f", filter.fixed()[0].Func)
+}
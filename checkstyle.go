@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/xml"
+	"go/token"
+	"io"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+// checkstyleVersion is the version of the Checkstyle XML format understood by the most of the consumers
+const checkstyleVersion = "4.3"
+
+type (
+	checkstyleReport struct {
+		XMLName xml.Name          `xml:"checkstyle"`
+		Version string            `xml:"version,attr"`
+		Files   []*checkstyleFile `xml:"file"`
+	}
+	checkstyleFile struct {
+		Name   string            `xml:"name,attr"`
+		Errors []checkstyleError `xml:"error"`
+	}
+	checkstyleError struct {
+		Line     int    `xml:"line,attr"`
+		Column   int    `xml:"column,attr,omitempty"`
+		Severity string `xml:"severity,attr"`
+		Message  string `xml:"message,attr"`
+		Source   string `xml:"source,attr"`
+	}
+)
+
+// checkstyleReporter groups findings by the files and writes them as a single Checkstyle XML document on Close
+type checkstyleReporter struct {
+	w            io.Writer
+	analysisPath string
+	report       checkstyleReport
+	files        map[string]*checkstyleFile
+}
+
+func newCheckstyleReporter(w io.Writer, analysisPath string) *checkstyleReporter {
+	return &checkstyleReporter{
+		w:            w,
+		analysisPath: analysisPath,
+		report:       checkstyleReport{Version: checkstyleVersion},
+		files:        make(map[string]*checkstyleFile),
+	}
+}
+
+func (r *checkstyleReporter) add(fset *token.FileSet, pos token.Pos, severity, rule, message string) {
+	position := fset.Position(pos)
+	fileName := position.Filename
+	if relativePath, ok := relativeFile(r.analysisPath, fileName); ok {
+		fileName = relativePath
+	}
+	file, ok := r.files[fileName]
+	if !ok {
+		file = &checkstyleFile{Name: fileName}
+		r.files[fileName] = file
+		r.report.Files = append(r.report.Files, file)
+	}
+	file.Errors = append(file.Errors, checkstyleError{
+		Line:     position.Line,
+		Column:   position.Column,
+		Severity: severity,
+		Message:  message,
+		Source:   analyzer.Analyzer.Name + "." + rule,
+	})
+}
+
+func (r *checkstyleReporter) Package(*packages.Package) {}
+
+func (r *checkstyleReporter) Warning(fset *token.FileSet, warning analyzer.Warning) {
+	if warning.Incomplete != nil {
+		r.add(fset, warning.Pos, "info", incompleteAnalysisRule, warningText(warning))
+		return
+	}
+	r.add(fset, warning.Pos, "warning", appendOverwriteRule, warningText(warning))
+}
+
+func (r *checkstyleReporter) UnusedSuppression(fset *token.FileSet, suppression analyzer.Suppression) {
+	r.add(fset, suppression.Pos, "warning", unusedSuppressionRule, unusedSuppressionMessage)
+}
+
+func (r *checkstyleReporter) Close() error {
+	return writeXml(r.w, r.report)
+}
+
+// writeXml writes indented XML document with the header
+func writeXml(w io.Writer, document any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+func TestCheckstyleReporter(t *testing.T) {
+	analysisPath := t.TempDir()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Join(analysisPath, "a.go"), `package pkg
+func f(prefix []int) ([]int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2) //gomakus:ignore
+	return a, b
+}`, parser.ParseComments)
+	require.NoError(t, err)
+	funcDecl := file.Decls[0]
+	position := func(line, column int) token.Pos { return fset.File(file.Pos()).LineStart(line) + token.Pos(column-1) }
+
+	var buffer bytes.Buffer
+	report := newCheckstyleReporter(&buffer, analysisPath)
+	report.Warning(fset, analyzer.Warning{Pos: position(4, 7), Variable: "prefix"})
+	report.Warning(fset, analyzer.Warning{Pos: funcDecl.Pos(), Incomplete: errors.New("too large")})
+	report.UnusedSuppression(fset, analyzer.Suppression{Pos: position(4, 25)})
+	require.NoError(t, report.Close())
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="a.go">
+    <error line="4" column="7" severity="warning" message="potential append overwrite found for prefix" source="gomakus.append-overwrite"></error>
+    <error line="2" column="1" severity="info" message="gomakus analysis of the function was cut short: too large" source="gomakus.incomplete-analysis"></error>
+    <error line="4" column="25" severity="warning" message="unused gomakus suppression directive" source="gomakus.unused-suppression"></error>
+  </file>
+</checkstyle>
+`, buffer.String())
+}
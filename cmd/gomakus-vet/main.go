@@ -0,0 +1,10 @@
+// gomakus-vet runs gomakus analyzer as a standalone tool or through go vet -vettool
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+func main() { singlechecker.Main(analyzer.Analyzer) }
@@ -1,16 +1,17 @@
 module github.com/sivukhin/gomakus
 
-go 1.21.0
+go 1.25.0
 
 require (
 	github.com/stretchr/testify v1.8.4
-	golang.org/x/tools v0.15.0
+	// go/packages reads dependencies from export data: v0.44.0 is the first release which decodes export data of go1.27 (it requires go 1.25.0)
+	golang.org/x/tools v0.44.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/mod v0.14.0 // indirect
-	golang.org/x/sys v0.14.0 // indirect
+	golang.org/x/mod v0.35.0 // indirect
+	golang.org/x/sync v0.20.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
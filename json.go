@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+type (
+	// jsonFinding is a single line of the JSON Lines report
+	jsonFinding struct {
+		jsonPosition
+		Func     string `json:"func,omitempty"`
+		Rule     string `json:"rule"`
+		Message  string `json:"message"`
+		Variable string `json:"variable,omitempty"`
+		// Conflict points to the earlier append which result can be overwritten
+		Conflict *jsonPosition `json:"conflict,omitempty"`
+		// Trace leads from the function declaration to the overwrite
+		Trace []jsonPosition `json:"trace,omitempty"`
+	}
+	// jsonPosition holds file path relative to the analysis path (or absolute path for the files outside of it)
+	jsonPosition struct {
+		File   string `json:"file"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	}
+)
+
+// jsonReporter writes every finding as a separate JSON object on its own line as soon as it is found
+type jsonReporter struct {
+	encoder      *json.Encoder
+	analysisPath string
+	err          error
+}
+
+func newJsonReporter(w io.Writer, analysisPath string) *jsonReporter {
+	return &jsonReporter{encoder: json.NewEncoder(w), analysisPath: analysisPath}
+}
+
+func (r *jsonReporter) position(fset *token.FileSet, pos token.Pos) jsonPosition {
+	position := fset.Position(pos)
+	fileName := position.Filename
+	if relativePath, ok := relativeFile(r.analysisPath, fileName); ok {
+		fileName = relativePath
+	}
+	return jsonPosition{File: fileName, Line: position.Line, Column: position.Column}
+}
+
+func (r *jsonReporter) write(finding jsonFinding) {
+	if r.err == nil {
+		r.err = r.encoder.Encode(finding)
+	}
+}
+
+func (r *jsonReporter) Package(*packages.Package) {}
+
+func (r *jsonReporter) Warning(fset *token.FileSet, warning analyzer.Warning) {
+	finding := jsonFinding{jsonPosition: r.position(fset, warning.Pos)}
+	if warning.FuncDecl != nil {
+		finding.Func = warning.FuncName()
+	}
+	if warning.Incomplete != nil {
+		finding.Rule, finding.Message = incompleteAnalysisRule, fmt.Sprintf("%v: %v", incompleteMessage, warning.Incomplete)
+		r.write(finding)
+		return
+	}
+	finding.Rule, finding.Message, finding.Variable = appendOverwriteRule, warningMessage, warning.Variable
+	if pos, ok := warning.ConflictPos(); ok {
+		conflict := r.position(fset, pos)
+		finding.Conflict = &conflict
+	}
+	for _, pos := range warning.TracePositions() {
+		finding.Trace = append(finding.Trace, r.position(fset, pos))
+	}
+	r.write(finding)
+}
+
+func (r *jsonReporter) UnusedSuppression(fset *token.FileSet, suppression analyzer.Suppression) {
+	r.write(jsonFinding{jsonPosition: r.position(fset, suppression.Pos), Rule: unusedSuppressionRule, Message: unusedSuppressionMessage})
+}
+
+func (r *jsonReporter) Close() error { return r.err }
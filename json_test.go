@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+func TestJsonReporter(t *testing.T) {
+	analysisPath := t.TempDir()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Join(analysisPath, "a.go"), `package pkg
+func f(prefix []int) ([]int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2)
+	return a, b
+}
+//gomakus:ignore
+func g() {}`, parser.ParseComments)
+	require.NoError(t, err)
+	config := analyzer.Config{Explain: true, ReportUnusedSuppressions: true}
+	warnings, _ := analyzer.AnalyzeFiles(config, fset, nil, []*ast.File{file}, nil)
+	warnings, unused := config.Suppress(fset, []*ast.File{file}, warnings)
+
+	var buffer bytes.Buffer
+	report := newJsonReporter(&buffer, analysisPath)
+	for _, warning := range warnings {
+		report.Warning(fset, warning)
+	}
+	for _, suppression := range unused {
+		report.UnusedSuppression(fset, suppression)
+	}
+	require.NoError(t, report.Close())
+
+	var findings []jsonFinding
+	scanner := bufio.NewScanner(&buffer)
+	for scanner.Scan() {
+		var finding jsonFinding
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &finding))
+		findings = append(findings, finding)
+	}
+	require.Equal(t, []jsonFinding{
+		{
+			jsonPosition: jsonPosition{File: "a.go", Line: 4, Column: 7},
+			Func:         "f",
+			Rule:         appendOverwriteRule,
+			Message:      warningMessage,
+			Variable:     "prefix",
+			Conflict:     &jsonPosition{File: "a.go", Line: 3, Column: 7},
+			Trace: []jsonPosition{
+				{File: "a.go", Line: 2, Column: 1},
+				{File: "a.go", Line: 3, Column: 7},
+				{File: "a.go", Line: 3, Column: 2},
+				{File: "a.go", Line: 4, Column: 7},
+			},
+		},
+		{
+			jsonPosition: jsonPosition{File: "a.go", Line: 7, Column: 1},
+			Rule:         unusedSuppressionRule,
+			Message:      unusedSuppressionMessage,
+		},
+	}, findings)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name          `xml:"testsuites"`
+		Name     string            `xml:"name,attr"`
+		Tests    int               `xml:"tests,attr"`
+		Failures int               `xml:"failures,attr"`
+		Skipped  int               `xml:"skipped,attr"`
+		Suites   []*junitTestSuite `xml:"testsuite"`
+	}
+	junitTestSuite struct {
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Skipped  int              `xml:"skipped,attr"`
+		Cases    []*junitTestCase `xml:"testcase"`
+	}
+	junitTestCase struct {
+		ClassName string        `xml:"classname,attr"`
+		Name      string        `xml:"name,attr"`
+		File      string        `xml:"file,attr,omitempty"`
+		Line      int           `xml:"line,attr,omitempty"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+		Skipped   *junitSkipped `xml:"skipped,omitempty"`
+		// findings holds number of the findings merged into the Failure
+		findings int
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",cdata"`
+	}
+	junitSkipped struct {
+		Message string `xml:"message,attr"`
+	}
+)
+
+// junitRange maps findings within the [from, to) range of the package files to the test case
+type junitRange struct {
+	from, to token.Pos
+	testCase *junitTestCase
+}
+
+// junitReporter represents every analyzed package as a test suite with a test case per function declaration
+// Findings become failures of the test case of the enclosing function (or of the test case of the file for the findings outside of the functions),
+// functions which analysis was cut short are skipped
+type junitReporter struct {
+	w            io.Writer
+	analysisPath string
+	report       junitTestSuites
+	suite        *junitTestSuite
+	ranges       []junitRange
+}
+
+func newJunitReporter(w io.Writer, analysisPath string) *junitReporter {
+	return &junitReporter{w: w, analysisPath: analysisPath, report: junitTestSuites{Name: analyzer.Analyzer.Name}}
+}
+
+// funcDeclName returns name of the function with the receiver type for methods: f, T.M or (*T).M
+func funcDeclName(funcDecl *ast.FuncDecl) string {
+	if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+		return funcDecl.Name.Name
+	}
+	recv := types.ExprString(funcDecl.Recv.List[0].Type)
+	if strings.HasPrefix(recv, "*") {
+		return fmt.Sprintf("(%v).%v", recv, funcDecl.Name.Name)
+	}
+	return fmt.Sprintf("%v.%v", recv, funcDecl.Name.Name)
+}
+
+func (r *junitReporter) fileName(fset *token.FileSet, pos token.Pos) string {
+	fileName := fset.Position(pos).Filename
+	if relativePath, ok := relativeFile(r.analysisPath, fileName); ok {
+		return relativePath
+	}
+	return fileName
+}
+
+func (r *junitReporter) Package(pkg *packages.Package) {
+	r.suite = &junitTestSuite{Name: pkg.PkgPath}
+	r.report.Suites = append(r.report.Suites, r.suite)
+	r.ranges = nil
+	for _, file := range pkg.Syntax {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || funcDecl.Body == nil {
+				continue
+			}
+			testCase := &junitTestCase{
+				ClassName: pkg.PkgPath,
+				Name:      funcDeclName(funcDecl),
+				File:      r.fileName(pkg.Fset, funcDecl.Pos()),
+				Line:      pkg.Fset.Position(funcDecl.Pos()).Line,
+			}
+			r.suite.Cases = append(r.suite.Cases, testCase)
+			// suppression directives in the doc comment belong to the function too
+			from := funcDecl.Pos()
+			if funcDecl.Doc != nil {
+				from = funcDecl.Doc.Pos()
+			}
+			r.ranges = append(r.ranges, junitRange{from: from, to: funcDecl.End(), testCase: testCase})
+		}
+	}
+}
+
+// testCase returns test case of the function which contains the position or creates test case of the whole file
+func (r *junitReporter) testCase(fset *token.FileSet, pos token.Pos) *junitTestCase {
+	for _, testCaseRange := range r.ranges {
+		if testCaseRange.from <= pos && pos < testCaseRange.to {
+			return testCaseRange.testCase
+		}
+	}
+	fileName := r.fileName(fset, pos)
+	for _, testCase := range r.suite.Cases {
+		if testCase.Name == fileName && testCase.Line == 0 {
+			return testCase
+		}
+	}
+	testCase := &junitTestCase{ClassName: r.suite.Name, Name: fileName, File: fileName}
+	r.suite.Cases = append(r.suite.Cases, testCase)
+	return testCase
+}
+
+func (r *junitReporter) fail(fset *token.FileSet, pos token.Pos, rule string, message string) {
+	testCase := r.testCase(fset, pos)
+	position := fset.Position(pos)
+	line := fmt.Sprintf("%v:%v:%v: %v", r.fileName(fset, pos), position.Line, position.Column, message)
+	testCase.findings++
+	if testCase.Failure == nil {
+		testCase.Failure = &junitFailure{Message: message, Type: rule, Text: line}
+		return
+	}
+	testCase.Failure.Message = fmt.Sprintf("%v gomakus findings", testCase.findings)
+	if testCase.Failure.Type != rule {
+		testCase.Failure.Type = analyzer.Analyzer.Name
+	}
+	testCase.Failure.Text += "\n" + line
+}
+
+func (r *junitReporter) Warning(fset *token.FileSet, warning analyzer.Warning) {
+	if warning.Incomplete != nil {
+		testCase := r.testCase(fset, warning.Pos)
+		if testCase.Skipped == nil {
+			testCase.Skipped = &junitSkipped{Message: warningText(warning)}
+		}
+		return
+	}
+	r.fail(fset, warning.Pos, appendOverwriteRule, warningText(warning))
+}
+
+func (r *junitReporter) UnusedSuppression(fset *token.FileSet, suppression analyzer.Suppression) {
+	r.fail(fset, suppression.Pos, unusedSuppressionRule, unusedSuppressionMessage)
+}
+
+func (r *junitReporter) Close() error {
+	for _, suite := range r.report.Suites {
+		for _, testCase := range suite.Cases {
+			if testCase.Failure != nil {
+				testCase.Skipped = nil // findings are more important than the incomplete analysis
+				suite.Failures++
+			} else if testCase.Skipped != nil {
+				suite.Skipped++
+			}
+		}
+		suite.Tests = len(suite.Cases)
+		r.report.Tests += suite.Tests
+		r.report.Failures += suite.Failures
+		r.report.Skipped += suite.Skipped
+	}
+	return writeXml(r.w, r.report)
+}
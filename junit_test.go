@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+func TestJunitReporter(t *testing.T) {
+	analysisPath := t.TempDir()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Join(analysisPath, "a.go"), `package pkg
+//gomakus:ignore
+var f = func() {}
+func (s *Stack) Push(v int) {}
+func g(prefix []int) ([]int, []int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2)
+	c := append(prefix, 3)
+	return a, b, c
+}
+func h() {}`, parser.ParseComments)
+	require.NoError(t, err)
+	position := func(line, column int) token.Pos { return fset.File(file.Pos()).LineStart(line) + token.Pos(column-1) }
+	push, g := file.Decls[1].(*ast.FuncDecl), file.Decls[2].(*ast.FuncDecl)
+
+	var buffer bytes.Buffer
+	report := newJunitReporter(&buffer, analysisPath)
+	report.Package(&packages.Package{PkgPath: "acme/pkg", Fset: fset, Syntax: []*ast.File{file}})
+	report.Warning(fset, analyzer.Warning{Pos: push.Pos(), FuncDecl: push, Incomplete: errors.New("too large")})
+	report.Warning(fset, analyzer.Warning{Pos: position(7, 7), FuncDecl: g, Variable: "prefix"})
+	report.Warning(fset, analyzer.Warning{Pos: position(8, 7), FuncDecl: g, Variable: "prefix"})
+	report.UnusedSuppression(fset, analyzer.Suppression{Pos: position(2, 1)})
+	require.NoError(t, report.Close())
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="gomakus" tests="4" failures="2" skipped="1">
+  <testsuite name="acme/pkg" tests="4" failures="2" skipped="1">
+    <testcase classname="acme/pkg" name="(*Stack).Push" file="a.go" line="4">
+      <skipped message="gomakus analysis of the function was cut short: too large"></skipped>
+    </testcase>
+    <testcase classname="acme/pkg" name="g" file="a.go" line="5">
+      <failure message="2 gomakus findings" type="append-overwrite"><![CDATA[a.go:7:7: potential append overwrite found for prefix
+a.go:8:7: potential append overwrite found for prefix]]></failure>
+    </testcase>
+    <testcase classname="acme/pkg" name="h" file="a.go" line="11"></testcase>
+    <testcase classname="acme/pkg" name="a.go" file="a.go">
+      <failure message="unused gomakus suppression directive" type="unused-suppression"><![CDATA[a.go:2:1: unused gomakus suppression directive]]></failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buffer.String())
+}
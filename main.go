@@ -1,36 +1,49 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
-	"go/ast"
 	"log"
+	"maps"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 
 	"golang.org/x/tools/go/packages"
 
+	"github.com/sivukhin/gomakus/analyzer"
 	"github.com/sivukhin/gomakus/src"
 )
 
-func reportWarning(format string, analysisPath string, fileName, funcName string, line int) {
-	if format == "github" {
-		relativePath, _ := filepath.Rel(analysisPath, fileName)
-		fmt.Printf("::warning file=%v,line=%v::%v\n", relativePath, line, "potential append overwrite found")
-	} else {
-		log.Printf(
-			"potential append overwrite found: func=[%v], file=[%v], line=[%v]",
-			funcName,
-			fileName,
-			line,
-		)
-	}
-}
-
 func main() {
 	modulePath := flag.String("path", "", "path to the module root (with go.mod file)")
-	reportFormat := flag.String("format", "log", "reporting type (github | log)")
-	flag.Parse()
+	reportFormat := flag.String("format", "log", "reporting type (github | log | sarif | json | checkstyle | junit)")
+	baselinePath := flag.String("baseline", "", "path to the baseline file: known findings are not reported and only new findings fail the run (written by 'gomakus baseline write')")
+	diffBase := flag.String("diff-base", "", "git revision to diff the working tree against: only changed functions are analyzed and only findings on the changed lines are reported, untracked files are treated as changed ('-' reads unified diff from stdin)")
+	var config analyzer.Config
+	config.RegisterFlags(flag.CommandLine)
+	// traces are enumerated only to explain the warnings in the machine-readable formats, so the limit isn't shared with the Analyzer flags
+	flag.IntVar(
+		&config.Limits.MaxTraces,
+		"max-traces",
+		0,
+		fmt.Sprintf("maximum number of execution traces enumerated to explain the warnings of single function in sarif and json formats (0 means %v)", analyzer.DefaultExplainMaxTraces),
+	)
+
+	// `gomakus baseline write [flags]` records current findings to the baseline instead of reporting them
+	args := os.Args[1:]
+	writeBaseline := len(args) > 0 && args[0] == "baseline"
+	if writeBaseline {
+		if len(args) < 2 || args[1] != "write" {
+			fmt.Println("unknown baseline command, expected 'gomakus baseline write'")
+			flag.Usage()
+			os.Exit(1)
+		}
+		args = args[2:]
+	}
+	_ = flag.CommandLine.Parse(args)
 
 	var analysisPath string
 	var err error
@@ -50,8 +63,42 @@ func main() {
 		}
 	}
 
+	if *diffBase != "" {
+		config.Changed, err = readChangedLines(analysisPath, *diffBase)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	report, err := newReporter(*reportFormat, analysisPath)
+	if err != nil {
+		fmt.Println(err)
+		flag.Usage()
+		os.Exit(1)
+	}
+	// traces are reported only in the machine-readable formats
+	config.Explain = *reportFormat == "sarif" || *reportFormat == "json"
+
+	var filter *baselineFilter
+	if writeBaseline {
+		if *baselinePath == "" {
+			*baselinePath = defaultBaselineFile
+		}
+		report, config.Explain = newBaselineWriter(*baselinePath, analysisPath), false
+	} else if *baselinePath != "" {
+		known, err := readBaseline(*baselinePath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		filter = newBaselineFilter(report, analysisPath, config, known)
+		report = filter
+	}
+
+	// dependencies are loaded from the export data: only packages of the module are parsed and type-checked from source
 	cfg := &packages.Config{
-		Mode:  packages.NeedSyntax | packages.NeedFiles | packages.NeedTypes,
+		Mode:  packages.NeedName | packages.NeedSyntax | packages.NeedFiles | packages.NeedImports | packages.NeedTypes | packages.NeedTypesInfo,
 		Tests: false,
 		Dir:   analysisPath,
 	}
@@ -62,31 +109,88 @@ func main() {
 	}
 
 	for _, pkg := range pkgs {
-		for _, file := range pkg.Syntax {
-			ast.Inspect(file, func(node ast.Node) bool {
-				funcDecl, ok := node.(*ast.FuncDecl)
-				if !ok {
-					return true
-				}
-				if funcDecl.Body == nil {
-					return true
-				}
-				execution := src.ExecutionFromFunc(src.NewScopes(map[string]src.FuncId{
-					src.SliceFuncName:  src.SliceFuncId,
-					src.AppendFuncName: src.AppendFuncId,
-				}), pkg.Fset, funcDecl)
-				_ = execution
-				warnings := src.ValidateExecution(src.DefaultFuncSpecCollection, execution)
-				for _, warning := range warnings {
-					pos, ok := execution.SourceCodeReferences.References[warning.ExecutionPoint]
-					if ok {
-						reportWarning(*reportFormat, analysisPath, pkg.Fset.Position(pos).Filename, funcDecl.Name.Name, pkg.Fset.Position(pos).Line)
-					} else {
-						reportWarning(*reportFormat, analysisPath, pkg.Fset.Position(pos).Filename, funcDecl.Name.Name, pkg.Fset.Position(funcDecl.Pos()).Line)
-					}
-				}
-				return true
-			})
+		if err := config.CheckFuncSpecs(pkg.TypesInfo); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
 		}
 	}
+
+	// packages of the module are analyzed in the dependency order, so specs of the dependencies are known when their callers are analyzed
+	// other dependencies are only visited, because their syntax isn't loaded
+	analyzed := make(map[*packages.Package]struct{}, len(pkgs))
+	for _, pkg := range pkgs {
+		analyzed[pkg] = struct{}{}
+	}
+	specs := make(map[string]src.FuncSpec)
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if _, ok := analyzed[pkg]; !ok {
+			return
+		}
+		annotations, errs := analyzer.FuncSpecAnnotations(pkg.TypesInfo, pkg.Syntax)
+		maps.Copy(specs, annotations)
+		report.Package(pkg)
+		for _, err := range errs {
+			log.Printf("%v: %v", pkg.Fset.Position(err.Pos), err)
+		}
+		warnings, pkgSpecs := analyzer.AnalyzeFiles(config, pkg.Fset, pkg.TypesInfo, pkg.Syntax, specs)
+		maps.Copy(specs, pkgSpecs)
+		warnings, unused := config.Suppress(pkg.Fset, pkg.Syntax, warnings)
+		warnings, unused = config.KeepChanged(pkg.Fset, warnings, unused)
+		for _, warning := range warnings {
+			report.Warning(pkg.Fset, warning)
+		}
+		for _, suppression := range unused {
+			report.UnusedSuppression(pkg.Fset, suppression)
+		}
+	})
+	if err := report.Close(); err != nil {
+		log.Fatalf("unable to write report: %v", err)
+	}
+	if filter != nil && filter.newFindings > 0 {
+		os.Exit(1)
+	}
+}
+
+// readChangedLines parses unified diff from stdin ("-") or the diff of the working tree against the git revision
+// Untracked files are missing in the git diff, so all their lines are considered changed
+// Paths of the diff are resolved relative to the analysis path
+func readChangedLines(analysisPath string, diffBase string) (analyzer.ChangedLines, error) {
+	if diffBase == "-" {
+		return analyzer.ParseUnifiedDiff(os.Stdin, analysisPath)
+	}
+	output, err := runGit(analysisPath,
+		"-c", "core.quotePath=false", "diff",
+		"--relative", "--no-color", "--no-ext-diff", "--src-prefix=a/", "--dst-prefix=b/", "-U0",
+		diffBase, "--",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("git diff against '%v' failed: %w", diffBase, err)
+	}
+	changed, err := analyzer.ParseUnifiedDiff(bytes.NewReader(output), analysisPath)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := runGit(analysisPath, "ls-files", "--others", "--exclude-standard", "-z", "--", ".")
+	if err != nil {
+		return nil, fmt.Errorf("unable to list untracked files: %w", err)
+	}
+	for _, fileName := range strings.Split(string(untracked), "\x00") {
+		if fileName != "" {
+			changed.AddFile(filepath.Join(analysisPath, filepath.FromSlash(fileName)))
+		}
+	}
+	return changed, nil
+}
+
+// runGit runs git command in the directory and returns its output (stderr is included in the error)
+func runGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return nil, fmt.Errorf("%v", strings.TrimSpace(string(exitErr.Stderr)))
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to run git: %w", err)
+	}
+	return output, nil
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+func TestReadChangedLines(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = root
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	write := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte(content), 0o644))
+	}
+	git("init", "-q")
+	write(".gitignore", "ignored.go\n")
+	write("pkg/a.go", "package pkg\n\nfunc a() {}\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+
+	write("pkg/a.go", "package pkg\n\nfunc a() { _ = 1 }\n")
+	write("pkg/b.go", "package pkg\n")
+	write("pkg/ignored.go", "package pkg\n")
+	write("c.go", "package main\n")
+
+	analysisPath := filepath.Join(root, "pkg")
+	changed, err := readChangedLines(analysisPath, "HEAD")
+	require.NoError(t, err)
+	require.Equal(t, analyzer.ChangedLines{
+		filepath.Join(analysisPath, "a.go"): {{From: 3, To: 3}},
+		filepath.Join(analysisPath, "b.go"): {{From: 1, To: math.MaxInt}},
+	}, changed)
+
+	_, err = readChangedLines(analysisPath, "unknown-revision")
+	require.ErrorContains(t, err, "git diff against 'unknown-revision' failed")
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+const (
+	warningMessage           = "potential append overwrite found"
+	unusedSuppressionMessage = "unused gomakus suppression directive"
+	incompleteMessage        = "gomakus analysis of the function was cut short"
+)
+
+// rule ids distinguish kinds of the findings in the machine-readable formats
+const (
+	appendOverwriteRule    = "append-overwrite"
+	unusedSuppressionRule  = "unused-suppression"
+	incompleteAnalysisRule = "incomplete-analysis"
+)
+
+// relativeFile returns slash-separated path of the file relative to the analysis path if the file is located within it
+func relativeFile(analysisPath string, fileName string) (string, bool) {
+	relativePath, err := filepath.Rel(analysisPath, fileName)
+	if err != nil || strings.HasPrefix(relativePath, "..") {
+		return "", false
+	}
+	return filepath.ToSlash(relativePath), true
+}
+
+// warningText describes the warning in a single line for the formats which have no dedicated fields for the details
+func warningText(warning analyzer.Warning) string {
+	if warning.Incomplete != nil {
+		return fmt.Sprintf("%v: %v", incompleteMessage, warning.Incomplete)
+	}
+	if warning.Variable != "" {
+		return fmt.Sprintf("%v for %v", warningMessage, warning.Variable)
+	}
+	return warningMessage
+}
+
+func reportWarning(format string, analysisPath string, fileName, funcName string, line int) {
+	if format == "github" {
+		relativePath, _ := filepath.Rel(analysisPath, fileName)
+		fmt.Printf("::warning file=%v,line=%v::%v\n", relativePath, line, warningMessage)
+	} else {
+		log.Printf(
+			"%v: func=[%v], file=[%v], line=[%v]",
+			warningMessage,
+			funcName,
+			fileName,
+			line,
+		)
+	}
+}
+
+func reportUnusedSuppression(format string, analysisPath string, fileName string, line int) {
+	if format == "github" {
+		relativePath, _ := filepath.Rel(analysisPath, fileName)
+		fmt.Printf("::warning file=%v,line=%v::%v\n", relativePath, line, unusedSuppressionMessage)
+	} else {
+		log.Printf("%v: file=[%v], line=[%v]", unusedSuppressionMessage, fileName, line)
+	}
+}
+
+func reportIncomplete(format string, analysisPath string, fileName, funcName string, line int, reason error) {
+	if format == "github" {
+		relativePath, _ := filepath.Rel(analysisPath, fileName)
+		fmt.Printf("::notice file=%v,line=%v::%v\n", relativePath, line, incompleteMessage)
+	} else {
+		log.Printf(
+			"%v: func=[%v], file=[%v], line=[%v], reason=[%v]",
+			incompleteMessage,
+			funcName,
+			fileName,
+			line,
+			reason,
+		)
+	}
+}
+
+// reporter receives findings of the analyzed packages and writes them in the specific format
+// To add new format implement reporter and register it in newReporter
+type reporter interface {
+	// Package is called for every analyzed package before its findings
+	Package(pkg *packages.Package)
+	Warning(fset *token.FileSet, warning analyzer.Warning)
+	UnusedSuppression(fset *token.FileSet, suppression analyzer.Suppression)
+	// Close is called after all packages are analyzed
+	Close() error
+}
+
+func newReporter(format string, analysisPath string) (reporter, error) {
+	switch format {
+	case "log", "github":
+		return textReporter{format: format, analysisPath: analysisPath}, nil
+	case "sarif":
+		return newSarifReporter(os.Stdout, analysisPath), nil
+	case "json":
+		return newJsonReporter(os.Stdout, analysisPath), nil
+	case "checkstyle":
+		return newCheckstyleReporter(os.Stdout, analysisPath), nil
+	case "junit":
+		return newJunitReporter(os.Stdout, analysisPath), nil
+	}
+	return nil, fmt.Errorf("unknown format '%v'", format)
+}
+
+// textReporter prints findings as soon as they are found (log & github formats)
+type textReporter struct {
+	format       string
+	analysisPath string
+}
+
+func (r textReporter) Package(*packages.Package) {}
+
+func (r textReporter) Warning(fset *token.FileSet, warning analyzer.Warning) {
+	position := fset.Position(warning.Pos)
+	if warning.Incomplete != nil {
+		reportIncomplete(r.format, r.analysisPath, position.Filename, warning.FuncName(), position.Line, warning.Incomplete)
+		return
+	}
+	reportWarning(r.format, r.analysisPath, position.Filename, warning.FuncName(), position.Line)
+}
+
+func (r textReporter) UnusedSuppression(fset *token.FileSet, suppression analyzer.Suppression) {
+	position := fset.Position(suppression.Pos)
+	reportUnusedSuppression(r.format, r.analysisPath, position.Filename, position.Line)
+}
+
+func (r textReporter) Close() error { return nil }
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"net/url"
+	"path/filepath"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	// sarifSrcRoot is the base id of the artifact locations relative to the analysis path
+	sarifSrcRoot = "SRCROOT"
+)
+
+// Subset of the SARIF 2.1.0 object model (https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html) used by the sarifReporter
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool               sarifTool                        `json:"tool"`
+		OriginalUriBaseIds map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+		Results            []sarifResult                    `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationUri string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		Id                   string             `json:"id"`
+		Name                 string             `json:"name"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		FullDescription      sarifMessage       `json:"fullDescription"`
+		HelpUri              string             `json:"helpUri"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	}
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifResult struct {
+		RuleId           string          `json:"ruleId"`
+		RuleIndex        int             `json:"ruleIndex"`
+		Level            string          `json:"level"`
+		Message          sarifMessage    `json:"message"`
+		Locations        []sarifLocation `json:"locations"`
+		RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+		CodeFlows        []sarifCodeFlow `json:"codeFlows,omitempty"`
+	}
+	sarifLocation struct {
+		// Id is set only for the related locations (ids start from 1, so zero value is omitted)
+		Id               int                    `json:"id,omitempty"`
+		PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+		LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+		Message          *sarifMessage          `json:"message,omitempty"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           sarifRegion           `json:"region"`
+	}
+	sarifArtifactLocation struct {
+		Uri       string `json:"uri"`
+		UriBaseId string `json:"uriBaseId,omitempty"`
+	}
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+	sarifLogicalLocation struct {
+		Name string `json:"name"`
+		Kind string `json:"kind"`
+	}
+	sarifCodeFlow struct {
+		ThreadFlows []sarifThreadFlow `json:"threadFlows"`
+	}
+	sarifThreadFlow struct {
+		Locations []sarifThreadFlowLocation `json:"locations"`
+	}
+	sarifThreadFlowLocation struct {
+		Location sarifLocation `json:"location"`
+	}
+)
+
+// indices of the sarifRules
+const (
+	sarifAppendOverwriteRule = iota
+	sarifUnusedSuppressionRule
+	sarifIncompleteAnalysisRule
+)
+
+var sarifRules = []sarifRule{
+	sarifAppendOverwriteRule: {
+		Id:                   appendOverwriteRule,
+		Name:                 "AppendOverwrite",
+		ShortDescription:     sarifMessage{Text: warningMessage},
+		FullDescription:      sarifMessage{Text: analyzer.Analyzer.Doc},
+		HelpUri:              analyzer.Analyzer.URL,
+		DefaultConfiguration: sarifConfiguration{Level: "warning"},
+	},
+	sarifUnusedSuppressionRule: {
+		Id:                   unusedSuppressionRule,
+		Name:                 "UnusedSuppression",
+		ShortDescription:     sarifMessage{Text: unusedSuppressionMessage},
+		FullDescription:      sarifMessage{Text: "//gomakus:ignore or //nolint:gomakus directive doesn't suppress any warning"},
+		HelpUri:              analyzer.Analyzer.URL,
+		DefaultConfiguration: sarifConfiguration{Level: "note"},
+	},
+	sarifIncompleteAnalysisRule: {
+		Id:                   incompleteAnalysisRule,
+		Name:                 "IncompleteAnalysis",
+		ShortDescription:     sarifMessage{Text: incompleteMessage},
+		FullDescription:      sarifMessage{Text: "function exceeded analysis limits or contains unsupported construct, so some warnings can be missed"},
+		HelpUri:              analyzer.Analyzer.URL,
+		DefaultConfiguration: sarifConfiguration{Level: "note"},
+	},
+}
+
+// sarifReporter accumulates findings and writes them as a single SARIF log on Close
+type sarifReporter struct {
+	w            io.Writer
+	analysisPath string
+	results      []sarifResult
+}
+
+func newSarifReporter(w io.Writer, analysisPath string) *sarifReporter {
+	return &sarifReporter{w: w, analysisPath: analysisPath, results: make([]sarifResult, 0)}
+}
+
+// location converts position to the SARIF location: files within the analysis path are referenced relative to the sarifSrcRoot
+func (r *sarifReporter) location(fset *token.FileSet, pos token.Pos) sarifLocation {
+	position := fset.Position(pos)
+	artifact := sarifArtifactLocation{Uri: fileUri(position.Filename)}
+	if relativePath, ok := relativeFile(r.analysisPath, position.Filename); ok {
+		artifact = sarifArtifactLocation{Uri: relativePath, UriBaseId: sarifSrcRoot}
+	}
+	return sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+		ArtifactLocation: artifact,
+		Region:           sarifRegion{StartLine: position.Line, StartColumn: position.Column},
+	}}
+}
+
+func fileUri(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func (r *sarifReporter) result(rule int, message string, location sarifLocation) sarifResult {
+	return sarifResult{
+		RuleId:    sarifRules[rule].Id,
+		RuleIndex: rule,
+		Level:     sarifRules[rule].DefaultConfiguration.Level,
+		Message:   sarifMessage{Text: message},
+		Locations: []sarifLocation{location},
+	}
+}
+
+func (r *sarifReporter) Package(*packages.Package) {}
+
+func (r *sarifReporter) Warning(fset *token.FileSet, warning analyzer.Warning) {
+	location := r.location(fset, warning.Pos)
+	if warning.FuncDecl != nil {
+		location.LogicalLocations = []sarifLogicalLocation{{Name: warning.FuncName(), Kind: "function"}}
+	}
+	if warning.Incomplete != nil {
+		message := fmt.Sprintf("%v: %v", incompleteMessage, warning.Incomplete)
+		r.results = append(r.results, r.result(sarifIncompleteAnalysisRule, message, location))
+		return
+	}
+	result := r.result(sarifAppendOverwriteRule, warningMessage, location)
+	if pos, ok := warning.ConflictPos(); ok {
+		related := r.location(fset, pos)
+		related.Id = 1
+		related.Message = &sarifMessage{Text: "earlier append which result can be overwritten"}
+		result.RelatedLocations = append(result.RelatedLocations, related)
+		result.Message.Text = "potential append overwrite of the result of the [earlier append](1) found"
+	}
+	if positions := warning.TracePositions(); len(positions) > 0 {
+		threadFlow := sarifThreadFlow{}
+		for _, pos := range positions {
+			threadFlow.Locations = append(threadFlow.Locations, sarifThreadFlowLocation{Location: r.location(fset, pos)})
+		}
+		result.CodeFlows = []sarifCodeFlow{{ThreadFlows: []sarifThreadFlow{threadFlow}}}
+	}
+	r.results = append(r.results, result)
+}
+
+func (r *sarifReporter) UnusedSuppression(fset *token.FileSet, suppression analyzer.Suppression) {
+	r.results = append(r.results, r.result(sarifUnusedSuppressionRule, unusedSuppressionMessage, r.location(fset, suppression.Pos)))
+}
+
+func (r *sarifReporter) Close() error {
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           analyzer.Analyzer.Name,
+				InformationUri: analyzer.Analyzer.URL,
+				Rules:          sarifRules,
+			}},
+			OriginalUriBaseIds: map[string]sarifArtifactLocation{sarifSrcRoot: {Uri: fileUri(r.analysisPath) + "/"}},
+			Results:            r.results,
+		}},
+	}
+	encoder := json.NewEncoder(r.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(log)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gomakus/analyzer"
+)
+
+func TestSarifReporter(t *testing.T) {
+	analysisPath := t.TempDir()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Join(analysisPath, "pkg", "a.go"), `package pkg
+func f(prefix []int, n int) ([]int, []int) {
+	a := prefix
+	if n > 0 {
+		a = append(a, 1)
+	}
+	b := append(prefix, 2) //gomakus:ignore
+	c := append(prefix, 3)
+	return a, c
+}`, parser.ParseComments)
+	require.NoError(t, err)
+	config := analyzer.Config{Explain: true, ReportUnusedSuppressions: true}
+	warnings, _ := analyzer.AnalyzeFiles(config, fset, nil, []*ast.File{file}, nil)
+	warnings, unused := config.Suppress(fset, []*ast.File{file}, warnings)
+	require.Len(t, warnings, 1)
+	require.Len(t, unused, 0)
+
+	var buffer bytes.Buffer
+	report := newSarifReporter(&buffer, analysisPath)
+	for _, warning := range warnings {
+		report.Warning(fset, warning)
+	}
+	report.UnusedSuppression(fset, analyzer.Suppression{Pos: file.Comments[0].Pos()})
+	require.NoError(t, report.Close())
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &log))
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	run := log.Runs[0]
+	require.Equal(t, "gomakus", run.Tool.Driver.Name)
+	require.Equal(t, fileUri(analysisPath)+"/", run.OriginalUriBaseIds[sarifSrcRoot].Uri)
+	require.Len(t, run.Results, 2)
+
+	overwrite := run.Results[0]
+	require.Equal(t, "append-overwrite", overwrite.RuleId)
+	require.Equal(t, "warning", overwrite.Level)
+	require.Equal(t, sarifArtifactLocation{Uri: "pkg/a.go", UriBaseId: sarifSrcRoot}, overwrite.Locations[0].PhysicalLocation.ArtifactLocation)
+	require.Equal(t, sarifRegion{StartLine: 8, StartColumn: 7}, overwrite.Locations[0].PhysicalLocation.Region)
+	require.Equal(t, []sarifLogicalLocation{{Name: "f", Kind: "function"}}, overwrite.Locations[0].LogicalLocations)
+	require.Len(t, overwrite.RelatedLocations, 1)
+	require.Equal(t, 1, overwrite.RelatedLocations[0].Id)
+	require.Equal(t, 5, overwrite.RelatedLocations[0].PhysicalLocation.Region.StartLine)
+	require.Len(t, overwrite.CodeFlows, 1)
+	var lines []int
+	for _, location := range overwrite.CodeFlows[0].ThreadFlows[0].Locations {
+		lines = append(lines, location.Location.PhysicalLocation.Region.StartLine)
+	}
+	require.Equal(t, 2, lines[0])
+	require.Contains(t, lines, 5)
+	require.Contains(t, lines, 8)
+
+	suppression := run.Results[1]
+	require.Equal(t, "unused-suppression", suppression.RuleId)
+	require.Equal(t, sarifUnusedSuppressionRule, suppression.RuleIndex)
+	require.Equal(t, "note", suppression.Level)
+	require.Equal(t, 7, suppression.Locations[0].PhysicalLocation.Region.StartLine)
+}
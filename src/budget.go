@@ -0,0 +1,115 @@
+package src
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// budgetCheckPeriod is a number of steps between checks of the wall time (time.Now is too expensive to call on every step)
+const budgetCheckPeriod = 1024
+
+// ErrBudgetExceeded is returned when analysis of the function was cut short by the Limits
+var ErrBudgetExceeded = errors.New("analysis budget exceeded")
+
+// ErrInternal is wrapped by the errors of the functions which analysis failed on the internal inconsistency (e.g. violated assertion)
+var ErrInternal = errors.New("internal analysis error")
+
+// recoverInternal converts panic raised during the analysis of single function to the error wrapping ErrInternal, so other functions are still analyzed
+func recoverInternal(err *error) {
+	if recovered := recover(); recovered != nil {
+		*err = fmt.Errorf("%w: %v", ErrInternal, recovered)
+	}
+}
+
+// Limits bounds the analysis of the single function; zero fields are not limited
+type Limits struct {
+	// MaxPoints limits number of points in the execution and its simplified form
+	MaxPoints int
+	// MaxTraces limits number of traces generated by ValidateExecutionTraces
+	MaxTraces int
+	// Timeout limits wall time of the analysis
+	Timeout time.Duration
+}
+
+// Budget tracks resources spent on the analysis of the single function
+// Analysis stops as soon as budget is exceeded and the reason is kept in Err; nil Budget is unlimited
+type Budget struct {
+	ctx      context.Context
+	limits   Limits
+	deadline time.Time
+	steps    int
+	err      error
+}
+
+func NewBudget(ctx context.Context, limits Limits) *Budget {
+	budget := &Budget{ctx: ctx, limits: limits}
+	if limits.Timeout > 0 {
+		budget.deadline = time.Now().Add(limits.Timeout)
+	}
+	return budget
+}
+
+// Err returns the reason why the analysis was cut short (it wraps ErrBudgetExceeded) or nil
+func (b *Budget) Err() error {
+	if b == nil {
+		return nil
+	}
+	return b.err
+}
+
+func (b *Budget) exceed(format string, args ...any) bool {
+	b.err = fmt.Errorf("%w: %v", ErrBudgetExceeded, fmt.Sprintf(format, args...))
+	return true
+}
+
+// step accounts single step of the analysis and returns true if the analysis must be stopped
+func (b *Budget) step() bool {
+	if b == nil {
+		return false
+	}
+	if b.err != nil {
+		return true
+	}
+	b.steps++
+	if b.steps%budgetCheckPeriod != 0 {
+		return false
+	}
+	if err := b.ctx.Err(); err != nil {
+		b.err = fmt.Errorf("%w: %w", ErrBudgetExceeded, err)
+		return true
+	}
+	if !b.deadline.IsZero() && time.Now().After(b.deadline) {
+		return b.exceed("timeout of %v exceeded", b.limits.Timeout)
+	}
+	return false
+}
+
+// points returns true if the execution has too many points to be analyzed
+func (b *Budget) points(execution Execution) bool {
+	if b == nil {
+		return false
+	}
+	if b.err != nil {
+		return true
+	}
+	if b.limits.MaxPoints > 0 && len(execution.Transitions) > b.limits.MaxPoints {
+		return b.exceed("%v execution points exceed limit of %v", len(execution.Transitions), b.limits.MaxPoints)
+	}
+	return false
+}
+
+// traces returns true if no more traces can be generated
+func (b *Budget) traces(count int) bool {
+	if b == nil {
+		return false
+	}
+	if b.err != nil {
+		return true
+	}
+	if b.limits.MaxTraces > 0 && count >= b.limits.MaxTraces {
+		return b.exceed("%v traces exceed limit of %v", count, b.limits.MaxTraces)
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package src
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gomakus/utils"
+)
+
+func manyBranchesExecution(branches int) Execution {
+	var body strings.Builder
+	for i := 0; i < branches; i++ {
+		body.WriteString(fmt.Sprintf("\tif n == %v {\n\t\ta = append(a, %v)\n\t}\n", i, i))
+	}
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, n int) ([]int, []int) {
+	a := prefix
+` + body.String() + `	return a, append(prefix, 1)
+}`)
+	return MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+}
+
+func TestBudgetUnlimited(t *testing.T) {
+	warnings, err := ValidateExecutionWithin(NewBudget(context.Background(), Limits{}), DefaultFuncSpecCollection, manyBranchesExecution(5))
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+}
+
+func TestBudgetMaxPoints(t *testing.T) {
+	execution := manyBranchesExecution(5)
+	budget := NewBudget(context.Background(), Limits{MaxPoints: 10})
+	_, err := ValidateExecutionWithin(budget, DefaultFuncSpecCollection, execution)
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	require.ErrorContains(t, err, "exceed limit of 10")
+
+	_, ok, err := InferFuncSpec(NewBudget(context.Background(), Limits{MaxPoints: 10}), DefaultFuncSpecCollection, execution)
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	require.False(t, ok)
+}
+
+func TestBudgetMaxTraces(t *testing.T) {
+	_, err := ValidateExecutionTraces(NewBudget(context.Background(), Limits{MaxTraces: 100}), DefaultFuncSpecCollection, manyBranchesExecution(30))
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	require.ErrorContains(t, err, "100 traces exceed limit of 100")
+}
+
+func TestBudgetTimeout(t *testing.T) {
+	start := time.Now()
+	_, err := ValidateExecutionTraces(NewBudget(context.Background(), Limits{Timeout: 10 * time.Millisecond}), DefaultFuncSpecCollection, manyBranchesExecution(30))
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	require.ErrorContains(t, err, "timeout of 10ms exceeded")
+	require.Less(t, time.Since(start), 10*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ValidateExecutionTraces(NewBudget(ctx, Limits{}), DefaultFuncSpecCollection, manyBranchesExecution(30))
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBudgetSelectorCycle(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+type node struct {
+	next *node
+	data []int
+}
+func last(l *node) *node {
+	p := l
+	for p.next != nil {
+		q := p.next
+		p = q
+	}
+	return p
+}
+func f(l *node) ([]int, []int) {
+	p := l
+	for p.next != nil {
+		q := p.next
+		p = q
+	}
+	a := append(p.data, 1)
+	b := append(p.data, 2)
+	return a, b
+}`)
+	execution := MustExecutionFromFunc(NewTypedScopes(DefaultFuncs, info), fset, utils.MustExtractFunc(file, "last"))
+	warnings, err := ValidateExecutionWithin(nil, DefaultFuncSpecCollection, execution)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+	_, ok, err := InferFuncSpec(nil, DefaultFuncSpecCollection, execution)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	execution = MustExecutionFromFunc(NewTypedScopes(DefaultFuncs, info), fset, utils.MustExtractFunc(file, "f"))
+	warnings, err = ValidateExecutionWithin(nil, DefaultFuncSpecCollection, execution)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	require.Equal(t, 21, fset.Position(execution.SourceCodeReferences.References[warnings[0].ExecutionPoint]).Line)
+}
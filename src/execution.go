@@ -21,6 +21,19 @@ type (
 		RootPoint            ExecutionPoint
 		Transitions          map[ExecutionPoint][]ExecutionTransition
 		SourceCodeReferences SourceCodeReferences
+		// Params contains variables of the receiver (if any) and parameters in the order of declaration (BlankVarId for unnamed ones)
+		// Receiver goes first in the same way as in the method specs
+		Params []VarId
+		// Results contains variables of the named results (BlankVarId for unnamed ones): naked return statement returns them
+		Results []VarId
+		// OutParams contains parameters which state remains visible to the caller after return (pointer receivers)
+		// Their state at the ExitPoint is inferred as FuncSpec.Updates
+		OutParams []VarId
+		// ExitPoint is the point where all returns of the function meet
+		ExitPoint ExecutionPoint
+		// FuncLits contains separate executions of the function literals defined within the function
+		// Immediately-invoked function literals are inlined into the Transitions instead
+		FuncLits []Execution
 	}
 	SourceCodeReferences struct {
 		Fset       *token.FileSet
@@ -2,12 +2,38 @@ package src
 
 import (
 	"go/token"
+	"slices"
+
+	"github.com/sivukhin/gomakus/utils"
 )
 
 type ExecutionBuilder struct {
 	CurrentPoint ExecutionPoint
 	lastPoint    *ExecutionPoint
 	execution    *Execution
+	// branchTargets is a stack of the enclosing statements which can be targeted by break / continue
+	branchTargets []branchTarget
+	// labels shared across whole function in order to support forward goto jumps
+	labels map[string]ExecutionPoint
+	// exitPoint is a single point where all return statements and calls of no-return functions jump
+	exitPoint *ExecutionPoint
+	// inlinedCall is set while building the body of the immediately-invoked function literal
+	inlinedCall *inlinedCall
+}
+
+// inlinedCall represents immediately-invoked function literal which body is built within the caller execution
+// return statements of the literal put results to the outputs and jump to the returnPoint
+type inlinedCall struct {
+	returnPoint ExecutionPoint
+	outputs     []VarId
+}
+
+// branchTarget represents loop, switch or select statement with the points where break / continue must jump
+// continuePoint is defined only for loops
+type branchTarget struct {
+	label         string
+	breakPoint    ExecutionPoint
+	continuePoint *ExecutionPoint
 }
 
 func NewExecutionBuilder(fset *token.FileSet) ExecutionBuilder {
@@ -25,6 +51,7 @@ func NewExecutionBuilder(fset *token.FileSet) ExecutionBuilder {
 				References: make(map[ExecutionPoint]token.Pos),
 			},
 		},
+		labels: make(map[string]ExecutionPoint),
 	}
 }
 
@@ -38,50 +65,132 @@ func (b ExecutionBuilder) AssignRef(point ExecutionPoint, position token.Pos) {
 
 func (b ExecutionBuilder) AcquirePoint() ExecutionBuilder {
 	*b.lastPoint += 1
-	return ExecutionBuilder{
-		CurrentPoint: *b.lastPoint,
-		lastPoint:    b.lastPoint,
-		execution:    b.execution,
-	}
+	b.CurrentPoint = *b.lastPoint
+	return b
 }
 
 func (b ExecutionBuilder) AcquirePointWithRef(position token.Pos) ExecutionBuilder {
 	*b.lastPoint += 1
 	b.AssignRef(*b.lastPoint, position)
-	return ExecutionBuilder{
-		CurrentPoint: *b.lastPoint,
-		lastPoint:    b.lastPoint,
-		execution:    b.execution,
-	}
+	b.CurrentPoint = *b.lastPoint
+	return b
 }
 
 func (b ExecutionBuilder) ConnectTo(point ExecutionPoint) ExecutionBuilder {
 	b.connect(ExecutionTransition{ToPoint: point, Operation: NoOp{}})
-	return ExecutionBuilder{
-		CurrentPoint: point,
-		lastPoint:    b.lastPoint,
-		execution:    b.execution,
-	}
+	b.CurrentPoint = point
+	return b
 }
 
 func (b ExecutionBuilder) ApplyNext(op Operation) ExecutionBuilder {
 	next := b.AcquirePoint()
 	b.connect(ExecutionTransition{ToPoint: next.CurrentPoint, Operation: op})
-	return ExecutionBuilder{
-		CurrentPoint: next.CurrentPoint,
-		lastPoint:    b.lastPoint,
-		execution:    b.execution,
-	}
+	b.CurrentPoint = next.CurrentPoint
+	return b
 }
 
 func (b ExecutionBuilder) ApplyNextWithRef(op Operation, position token.Pos) ExecutionBuilder {
 	next := b.AcquirePointWithRef(position)
 	b.connect(ExecutionTransition{ToPoint: next.CurrentPoint, Operation: op})
-	return ExecutionBuilder{
-		CurrentPoint: next.CurrentPoint,
-		lastPoint:    b.lastPoint,
-		execution:    b.execution,
+	b.CurrentPoint = next.CurrentPoint
+	return b
+}
+
+// PushBranchTarget registers statement which can be targeted by break (and continue if continuePoint is not nil) within the nested statements
+func (b ExecutionBuilder) PushBranchTarget(label string, breakPoint ExecutionPoint, continuePoint *ExecutionPoint) ExecutionBuilder {
+	b.branchTargets = append(slices.Clip(b.branchTargets), branchTarget{
+		label:         label,
+		breakPoint:    breakPoint,
+		continuePoint: continuePoint,
+	})
+	return b
+}
+
+// LabelPoint returns point of the labeled statement (point can be acquired before the statement itself in case of the forward goto)
+func (b ExecutionBuilder) LabelPoint(label string) ExecutionPoint {
+	point, ok := b.labels[label]
+	if !ok {
+		point = b.AcquirePoint().CurrentPoint
+		b.labels[label] = point
 	}
+	return point
+}
+
+// Break jumps to the end of the innermost (or labeled) loop, switch or select
+// Returned builder points to the unreachable point, so all statements after break will be excluded from the execution
+// False is returned if there is no such target
+func (b ExecutionBuilder) Break(label string) (ExecutionBuilder, bool) {
+	for i := len(b.branchTargets) - 1; i >= 0; i-- {
+		if label == "" || b.branchTargets[i].label == label {
+			return b.JumpTo(b.branchTargets[i].breakPoint), true
+		}
+	}
+	return b, false
+}
+
+// Continue jumps to the next iteration of the innermost (or labeled) loop
+// False is returned if there is no such target
+func (b ExecutionBuilder) Continue(label string) (ExecutionBuilder, bool) {
+	for i := len(b.branchTargets) - 1; i >= 0; i-- {
+		target := b.branchTargets[i]
+		if target.continuePoint != nil && (label == "" || target.label == label) {
+			return b.JumpTo(*target.continuePoint), true
+		}
+	}
+	return b, false
+}
+
+// Goto jumps to the labeled statement
+func (b ExecutionBuilder) Goto(label string) ExecutionBuilder {
+	return b.JumpTo(b.LabelPoint(label))
+}
+
+// WithExitPoint sets the point where Exit will jump
+func (b ExecutionBuilder) WithExitPoint(point ExecutionPoint) ExecutionBuilder {
+	b.exitPoint = &point
+	return b
+}
+
+// Exit terminates current path by the jump to the exit point
+func (b ExecutionBuilder) Exit() ExecutionBuilder {
+	utils.Assertf(b.exitPoint != nil, "exit point must be set")
+	return b.JumpTo(*b.exitPoint)
+}
+
+// InlineCall returns builder for the body of the immediately-invoked function literal
+// Labels and branch targets of the caller are hidden from the body and return statements will jump to the returnPoint
+func (b ExecutionBuilder) InlineCall(returnPoint ExecutionPoint, outputs []VarId) ExecutionBuilder {
+	b.branchTargets = nil
+	b.labels = make(map[string]ExecutionPoint)
+	b.inlinedCall = &inlinedCall{returnPoint: returnPoint, outputs: outputs}
+	return b
+}
+
+// InlinedCallOutputs returns variables where return statement must put results of the inlined function literal
+func (b ExecutionBuilder) InlinedCallOutputs() ([]VarId, bool) {
+	if b.inlinedCall == nil {
+		return nil, false
+	}
+	return b.inlinedCall.outputs, true
+}
+
+// Return terminates current path of the function body (or the body of the inlined function literal)
+func (b ExecutionBuilder) Return() ExecutionBuilder {
+	if b.inlinedCall != nil {
+		return b.JumpTo(b.inlinedCall.returnPoint)
+	}
+	return b.Exit()
+}
+
+// AddFuncLit attaches separate execution of the function literal defined within the current function
+func (b ExecutionBuilder) AddFuncLit(execution Execution) {
+	(*b.execution).FuncLits = append((*b.execution).FuncLits, execution)
+}
+
+// JumpTo connects current point to the given one and returns builder at the fresh unreachable point
+func (b ExecutionBuilder) JumpTo(point ExecutionPoint) ExecutionBuilder {
+	b.connect(ExecutionTransition{ToPoint: point, Operation: NoOp{}})
+	return b.AcquirePoint()
 }
 
 func (b ExecutionBuilder) connect(transition ExecutionTransition) {
@@ -0,0 +1,198 @@
+package src
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// DataflowLagLimit bounds lags tracked by the dataflow analysis: bigger lags are widened to the limit, so the fixpoint is reached for the loops
+const DataflowLagLimit = 2
+
+// dataflowOrigin identifies the backing array: it is either fresh value allocated at the Point or initial value of the variable
+type dataflowOrigin struct {
+	Point ExecutionPoint
+	VarId VarId
+	// Old summarizes all values allocated at the Point before the latest one (e.g. on the previous loop iterations)
+	Old bool
+}
+
+// dataflowGen is an abstract generation of the variable: as the warning depends only on the distance between generation of the variable and
+// the latest generation of its origin (see ValidateTrace), only the Lag between them is tracked
+type dataflowGen struct {
+	Origin dataflowOrigin
+	Lag    int
+	// Unset marks initial value of the variable which wasn't read yet: its origin has no latest generation
+	Unset bool
+}
+
+// varGens holds all abstract generations which variables can have at the execution point
+type varGens[G comparable] map[VarId]map[G]struct{}
+
+func (s varGens[G]) clone() varGens[G] {
+	clone := make(varGens[G], len(s))
+	for varId, gens := range s {
+		clone[varId] = maps.Clone(gens)
+	}
+	return clone
+}
+
+// join merges other state into s and returns true if s was changed
+func (s varGens[G]) join(other varGens[G]) bool {
+	changed := false
+	for varId, gens := range other {
+		if _, ok := s[varId]; !ok {
+			s[varId] = make(map[G]struct{}, len(gens))
+		}
+		for gen := range gens {
+			if _, ok := s[varId][gen]; !ok {
+				s[varId][gen] = struct{}{}
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// assignedVars returns all variables assigned or read by AssignVarOp operations of the execution
+func assignedVars(execution Execution) []VarId {
+	var varIds []VarId
+	for _, transitions := range execution.Transitions {
+		for _, transition := range transitions {
+			if op, ok := transition.Operation.(AssignVarOp); ok {
+				for _, varId := range []VarId{op.FromVarId, op.ToVarId} {
+					if varId != BlankVarId {
+						varIds = append(varIds, varId)
+					}
+				}
+			}
+		}
+	}
+	return varIds
+}
+
+// propagateDataflow applies transfer to the states of the execution points reachable from the root until they stop changing
+// Transfer must be monotone and must not modify the state passed to it; propagation stops early if the budget is exceeded
+func propagateDataflow[G comparable](
+	budget *Budget,
+	execution Execution,
+	root varGens[G],
+	transfer func(state varGens[G], transition ExecutionTransition) varGens[G],
+) {
+	states := map[ExecutionPoint]varGens[G]{execution.RootPoint: root}
+	queue := []ExecutionPoint{execution.RootPoint}
+	queued := map[ExecutionPoint]bool{execution.RootPoint: true}
+	for len(queue) > 0 && !budget.step() {
+		point := queue[0]
+		queue, queued[point] = queue[1:], false
+		for _, transition := range execution.Transitions[point] {
+			next := transfer(states[point], transition)
+			_, visited := states[transition.ToPoint]
+			if !visited {
+				states[transition.ToPoint] = make(varGens[G])
+			}
+			if (states[transition.ToPoint].join(next) || !visited) && !queued[transition.ToPoint] {
+				queue, queued[transition.ToPoint] = append(queue, transition.ToPoint), true
+			}
+		}
+	}
+}
+
+type dataflowState = varGens[dataflowGen]
+
+// updateOrigin replaces every generation of the origin with the result of f
+func updateOrigin(s dataflowState, origin dataflowOrigin, f func(gen dataflowGen) dataflowGen) {
+	for varId, gens := range s {
+		var updated []dataflowGen
+		for gen := range gens {
+			if gen.Origin == origin && !gen.Unset {
+				updated = append(updated, gen)
+			}
+		}
+		if len(updated) == 0 {
+			continue
+		}
+		for _, gen := range updated {
+			delete(gens, gen)
+		}
+		for _, gen := range updated {
+			s[varId][f(gen)] = struct{}{}
+		}
+	}
+}
+
+func widenLag(lag int) int { return max(-DataflowLagLimit, min(DataflowLagLimit, lag)) }
+
+// assign applies AssignVarOp to the state in the same way as ValidateTrace does and returns true if it leads to the overwrite for some generations
+func assignDataflow(s dataflowState, op AssignVarOp, point ExecutionPoint) (dataflowState, bool) {
+	if op.ToVarId == BlankVarId {
+		return s, false
+	}
+	if op.FromVarId == BlankVarId {
+		next := s.clone()
+		origin := dataflowOrigin{Point: point, VarId: BlankVarId}
+		updateOrigin(next, origin, func(gen dataflowGen) dataflowGen {
+			gen.Origin.Old = true
+			return gen
+		})
+		next[op.ToVarId] = map[dataflowGen]struct{}{{Origin: origin}: {}}
+		return next, false
+	}
+	result := make(dataflowState, len(s))
+	warning := false
+	for gen := range s[op.FromVarId] {
+		next := s.clone()
+		target := dataflowGen{Origin: gen.Origin}
+		if gen.Unset {
+			// first read of the initial value: generation of the target becomes the latest one
+			next[op.FromVarId] = map[dataflowGen]struct{}{{Origin: gen.Origin, Lag: widenLag(int(op.GenChange))}: {}}
+		} else {
+			next[op.FromVarId] = map[dataflowGen]struct{}{gen: {}}
+			lag := gen.Lag - int(op.GenChange)
+			if lag < 0 {
+				// target generation becomes the latest one, so all other variables of the origin lag behind it
+				updateOrigin(next, gen.Origin, func(other dataflowGen) dataflowGen {
+					other.Lag = widenLag(other.Lag - lag)
+					return other
+				})
+				lag = 0
+			} else if op.GenChange == NextGen {
+				warning = true
+			}
+			target.Lag = widenLag(lag)
+		}
+		next[op.ToVarId] = map[dataflowGen]struct{}{target: {}}
+		result.join(next)
+	}
+	return result, warning
+}
+
+// ValidateDataflow finds execution points where the overwrite can happen by propagating generations of the variables to the fixpoint
+// It reports the same points as ValidateTrace applied to all traces of the execution, but its complexity is polynomial in the execution size
+// If the budget is exceeded only points found before that are reported
+func ValidateDataflow(budget *Budget, execution Execution) []ExecutionPoint {
+	root := make(dataflowState)
+	for _, varId := range assignedVars(execution) {
+		root[varId] = map[dataflowGen]struct{}{{Origin: dataflowOrigin{Point: -1, VarId: varId}, Unset: true}: {}}
+	}
+	warnings := make(map[ExecutionPoint]struct{})
+	propagateDataflow(budget, execution, root, func(state dataflowState, transition ExecutionTransition) dataflowState {
+		switch op := transition.Operation.(type) {
+		case AssignVarOp:
+			next, warning := assignDataflow(state, op, transition.ToPoint)
+			if warning {
+				warnings[transition.ToPoint] = struct{}{}
+			}
+			return next
+		case NoOp:
+			return state
+		}
+		panic(fmt.Errorf("unexpected execution statement type(%T): %#v", transition, transition))
+	})
+	points := make([]ExecutionPoint, 0, len(warnings))
+	for point := range warnings {
+		points = append(points, point)
+	}
+	slices.Sort(points)
+	return points
+}
@@ -0,0 +1,121 @@
+package src
+
+import (
+	"fmt"
+	"go/ast"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gomakus/utils"
+)
+
+func TestValidateDataflowMatchesTraces(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+type Pair struct{ Left, Right []int }
+func appendTwice(prefix []int) ([]int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2)
+	return a, b
+}
+func appendSequence(prefix []int) []int {
+	prefix = append(prefix, 1)
+	prefix = append(prefix, 2)
+	return prefix
+}
+func appendInLoop(prefix []int, n int) [][]int {
+	var ret [][]int
+	for i := 0; i < n; i++ {
+		next := append(prefix[:], i)
+		ret = append(ret, next)
+	}
+	return ret
+}
+func appendToFreshInLoop(n int) [][]int {
+	var ret [][]int
+	for i := 0; i < n; i++ {
+		buf := make([]int, 0, 1)
+		buf = append(buf, i)
+		ret = append(ret, buf)
+	}
+	return ret
+}
+func appendAfterBranch(prefix []int, ok bool) ([]int, []int) {
+	if ok {
+		prefix = append(prefix, 1)
+	}
+	a := append(prefix, 2)
+	if !ok {
+		return a, nil
+	}
+	return a, append(prefix, 3)
+}
+func appendAfterReslice(prefix []int) ([]int, []int) {
+	a := append(prefix, 1)
+	b := append(a[:len(a)-1:len(a)-1], 2)
+	c := append(a[:len(a):len(a)], 3)
+	return b, c
+}
+func appendFields(prefix []int) ([]int, []int) {
+	p := Pair{nil, prefix}
+	q := append(p.Left, 1)
+	r := append(p.Right, 2)
+	s := append(prefix, 3)
+	return q, append(r, s...)
+}
+func appendInSwitch(prefix []int, n int) ([]int, []int) {
+	a := prefix
+	switch n {
+	case 0:
+		a = nil
+	case 1:
+		a = append(a, 1)
+	}
+	for j := 0; j < n; j++ {
+		a = append(a, j)
+	}
+	return a, append(prefix, 2)
+}`)
+	scopes := NewTypedScopes(DefaultFuncs, info)
+	points := func(warnings []ValidationWarning) []ExecutionPoint {
+		var points []ExecutionPoint
+		for _, warning := range warnings {
+			points = append(points, warning.ExecutionPoint)
+		}
+		slices.Sort(points)
+		return slices.Compact(points)
+	}
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		execution := MustExecutionFromFunc(scopes, fset, funcDecl)
+		traceWarnings, err := ValidateExecutionTraces(nil, DefaultFuncSpecCollection, execution)
+		require.NoError(t, err)
+		require.Equal(
+			t,
+			points(traceWarnings),
+			points(ValidateExecution(DefaultFuncSpecCollection, execution)),
+			funcDecl.Name.Name,
+		)
+	}
+}
+
+func TestValidateDataflowManyBranches(t *testing.T) {
+	var body strings.Builder
+	for i := 0; i < 30; i++ {
+		body.WriteString(fmt.Sprintf("\tif n == %v {\n\t\ta = append(a, %v)\n\t}\n", i, i))
+	}
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, n int) ([]int, []int) {
+	a := prefix
+` + body.String() + `	return a, append(prefix, 1)
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	start := time.Now()
+	require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+	require.Less(t, time.Since(start), 10*time.Second)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 
 	"github.com/sivukhin/gomakus/utils"
 )
@@ -22,7 +23,7 @@ func deconstructIf(fset *token.FileSet, ifStmt *ast.IfStmt) (inits []ast.Stmt, b
 		} else if ifStmt.Else == nil {
 			break
 		} else {
-			panic(fmt.Errorf("unexpected if/else structure: %#v", fset.Position(ifStmt.Pos())))
+			unsupported(ifStmt.Else, "unexpected if/else structure")
 		}
 	}
 	utils.Assertf(len(inits) == len(bodies), "deconstructIf invariant failed at %v", fset.Position(ifStmt.Pos()))
@@ -30,8 +31,8 @@ func deconstructIf(fset *token.FileSet, ifStmt *ast.IfStmt) (inits []ast.Stmt, b
 }
 
 // deconstructDecl recognizes two declaration methods: var x string = "s" and x := "s"
-func deconstructDecl(stmt ast.Stmt) ([]string, []ast.Expr, bool) {
-	names, values := make([]string, 0), make([]ast.Expr, 0)
+func deconstructDecl(stmt ast.Stmt) ([]*ast.Ident, []ast.Expr, bool) {
+	names, values := make([]*ast.Ident, 0), make([]ast.Expr, 0)
 	if decl, ok := stmt.(*ast.DeclStmt); ok {
 		genDecl := decl.Decl.(*ast.GenDecl)
 		if genDecl.Tok != token.VAR { // ast.GenDecl also includes const def, imports and type def
@@ -40,7 +41,7 @@ func deconstructDecl(stmt ast.Stmt) ([]string, []ast.Expr, bool) {
 		for _, spec := range genDecl.Specs {
 			valueSpec := spec.(*ast.ValueSpec) // under var declaration we must encounter only ValueSpecs
 			for i, name := range valueSpec.Names {
-				names = append(names, name.Name)
+				names = append(names, name)
 				if len(valueSpec.Values) == 0 { // e.g. var x, y, z string
 					values = append(values, nil)
 				} else {
@@ -52,7 +53,7 @@ func deconstructDecl(stmt ast.Stmt) ([]string, []ast.Expr, bool) {
 	}
 	if assign, ok := stmt.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE {
 		for _, lhs := range assign.Lhs {
-			names = append(names, lhs.(*ast.Ident).Name)
+			names = append(names, lhs.(*ast.Ident))
 		}
 		for _, rhs := range assign.Rhs {
 			values = append(values, rhs)
@@ -84,6 +85,59 @@ func executionFromVarComposition(
 	return builder
 }
 
+// compositeLitFields returns field names of the struct literal (names can be unknown without type information)
+// If literal is not a struct (array, slice or map) - false will be returned
+func compositeLitFields(scopes Scopes, lit *ast.CompositeLit) ([]string, bool) {
+	if scopes.Info != nil {
+		if litType := scopes.Info.TypeOf(lit); litType != nil {
+			structType, ok := litType.Underlying().(*types.Struct)
+			if !ok {
+				return nil, false
+			}
+			names := make([]string, 0, structType.NumFields())
+			for i := 0; i < structType.NumFields(); i++ {
+				names = append(names, structType.Field(i).Name())
+			}
+			return names, true
+		}
+	}
+	switch lit.Type.(type) {
+	case *ast.ArrayType, *ast.MapType:
+		return nil, false
+	}
+	return nil, true
+}
+
+// executionFromCompositeLit embeds every struct literal field value at the path of the field name: T{a: x, b: U{c: y}} -> {a: x, b:c: y}
+// Array, slice and map literals always produce fresh value and only their elements are evaluated
+func executionFromCompositeLit(
+	builder ExecutionBuilder,
+	scopes Scopes,
+	fset *token.FileSet,
+	lit *ast.CompositeLit,
+) (ExecutionBuilder, VarComposition) {
+	fields, isStruct := compositeLitFields(scopes, lit)
+	var varComposition VarComposition
+	for i, element := range lit.Elts {
+		name, value := "", element
+		if keyValue, ok := element.(*ast.KeyValueExpr); ok {
+			if keyIdent, ok := keyValue.Key.(*ast.Ident); ok {
+				name = keyIdent.Name
+			}
+			value = keyValue.Value
+		} else if i < len(fields) {
+			name = fields[i]
+		}
+		var valueVarComposition []VarComposition
+		builder, valueVarComposition = executionFromExpr(builder, scopes, fset, value, 1)
+		assertSupported(len(valueVarComposition) == 1, element, "composite lit element must have single value")
+		if isStruct && name != "" {
+			varComposition = append(varComposition, valueVarComposition[0].Embed(name)...)
+		}
+	}
+	return builder, varComposition
+}
+
 func executionFromExpr(
 	builder ExecutionBuilder,
 	scopes Scopes,
@@ -100,12 +154,12 @@ func executionFromExpr(
 	case *ast.ParenExpr:
 		return executionFromExpr(builder, scopes, fset, e.X, exprOutputs)
 	case *ast.Ident:
-		utils.Assertf(exprOutputs == 1, "unexpected multi-output expression: %v", fset.Position(expr.Pos()))
-		return builder, []VarComposition{{{VarSelector: VarSelector{VarId: scopes.GetVarOrBlank(e.Name)}}}}
+		assertSupported(exprOutputs == 1, expr, "identifier must be used as single value")
+		return builder, []VarComposition{{{VarSelector: VarSelector{VarId: scopes.GetVarOrBlank(e)}}}}
 	case *ast.SelectorExpr:
 		var varCompositions []VarComposition
 		builder, varCompositions = executionFromExpr(builder, scopes, fset, e.X, 1)
-		utils.Assertf(len(varCompositions) == 1, "selector must be applied to single value")
+		assertSupported(len(varCompositions) == 1, e, "selector must be applied to single value")
 		for _, varEmbed := range varCompositions[0] {
 			if selected, ok := varEmbed.Select(e.Sel.Name); ok {
 				return builder, []VarComposition{{selected}}
@@ -113,44 +167,53 @@ func executionFromExpr(
 		}
 		return builder, blanks
 	case *ast.CompositeLit:
+		var varComposition VarComposition
+		builder, varComposition = executionFromCompositeLit(builder, scopes, fset, e)
+		if len(varComposition) == 0 {
+			return builder, blanks
+		}
+		return builder, []VarComposition{varComposition}
+	case *ast.UnaryExpr:
+		if e.Op == token.AND { // &x and &T{...} share all components with x and T{...}
+			return executionFromExpr(builder, scopes, fset, e.X, exprOutputs)
+		}
 		return builder, blanks
-		//if _, ok := e.Type.(*ast.ArrayType); ok {
-		//	return builder, blanks
-		//}
-		//var varCompositions VarComposition
-		//for _, el := range e.Elts {
-		//	keyValueExp, ok := el.(*ast.KeyValueExpr)
-		//	if !ok {
-		//		continue
-		//	}
-		//	keyIdent, ok := keyValueExp.Key.(*ast.Ident)
-		//	if !ok {
-		//		continue
-		//	}
-		//	var nestedVarComposition []VarComposition
-		//	builder, nestedVarComposition = executionFromExpr(builder, scopes, fset, keyValueExp.Value, 1)
-		//	utils.Assertf(len(nestedVarComposition) == 1, "composite lit key must be assigned to single value")
-		//	varCompositions = append(varCompositions, nestedVarComposition[0].Embed(keyIdent.Name)...)
-		//}
-		//return builder, []VarComposition{varCompositions}
 	case *ast.CallExpr, *ast.SliceExpr:
 		var funcId FuncId
 		var args []ast.Expr
 		if call, isCall := e.(*ast.CallExpr); isCall {
-			funcIdent, ok := call.Fun.(*ast.Ident)
-			if ok {
-				funcId, ok = scopes.TryGetFunc(funcIdent.Name)
+			if lit, ok := ast.Unparen(call.Fun).(*ast.FuncLit); ok {
+				return executionFromInlinedCall(builder, scopes, fset, call, lit, exprOutputs)
 			}
+			if shared, isConversion := scopes.Conversion(call); isConversion {
+				if shared {
+					return executionFromExpr(builder, scopes, fset, call.Args[0], exprOutputs)
+				}
+				builder, _ = executionFromExpr(builder, scopes, fset, call.Args[0], 1)
+				return builder, blanks
+			}
+			var receiver ast.Expr
+			var ok bool
+			funcId, receiver, ok = scopes.TryGetCalledFunc(call.Fun)
 			if !ok {
+				// arguments of the unknown function still must be evaluated: sort.Slice(x, func(i, j int) bool { ... })
+				for _, arg := range call.Args {
+					builder, _ = executionFromExpr(builder, scopes, fset, arg, 1)
+				}
 				return builder, blanks
 			}
 			args = call.Args
+			if receiver != nil {
+				args = append([]ast.Expr{receiver}, call.Args...)
+			}
 		} else {
 			slice := e.(*ast.SliceExpr)
 			if slice.Max == nil {
 				return executionFromExpr(builder, scopes, fset, slice.X, 1)
 			}
-			funcId = scopes.GetFunc(SliceFuncName)
+			var ok bool
+			funcId, ok = scopes.GetFunc(SliceFuncName)
+			assertSupported(ok, e, "func %v is not registered in the scope", SliceFuncName)
 			args = []ast.Expr{slice.X}
 		}
 		var inVarCompositions []VarComposition
@@ -159,7 +222,7 @@ func executionFromExpr(
 		for _, arg := range args {
 			var argVarComposition []VarComposition
 			builder, argVarComposition = executionFromExpr(builder, scopes, fset, arg, 1)
-			utils.Assertf(len(argVarComposition) == 1, "argument expression must have single value: %v", fset.Position(e.Pos()))
+			assertSupported(len(argVarComposition) == 1, arg, "argument expression must have single value")
 			inVarCompositions = append(inVarCompositions, argVarComposition[0])
 		}
 		for i := 0; i < exprOutputs; i++ {
@@ -173,17 +236,18 @@ func executionFromExpr(
 			Outputs: outVars,
 		}, expr.Pos())
 		return builder, outVarCompositions
+	case *ast.FuncLit:
+		builder.AddFuncLit(executionFromFuncBody(scopes, fset, e.Pos(), nil, e.Type, e.Body))
+		return builder, blanks
 	case
 		nil,
 		*ast.StructType,
 		*ast.Ellipsis,
 		*ast.BasicLit,
-		*ast.FuncLit,
 		*ast.IndexExpr,
 		*ast.IndexListExpr,
 		*ast.TypeAssertExpr,
 		*ast.StarExpr,
-		*ast.UnaryExpr,
 		*ast.BinaryExpr,
 		*ast.KeyValueExpr,
 		*ast.ArrayType,
@@ -193,7 +257,8 @@ func executionFromExpr(
 		*ast.ChanType:
 		return builder, blanks
 	}
-	panic(fmt.Errorf("unexpected expression"))
+	unsupported(expr, "unexpected expression %T", expr)
+	return builder, blanks
 }
 
 func executionFromStmtList(
@@ -209,10 +274,6 @@ func executionFromStmtList(
 	return builder
 }
 
-func f() error {
-	return nil
-}
-
 func executionFromStmt(
 	builder ExecutionBuilder,
 	scopes Scopes,
@@ -222,7 +283,29 @@ func executionFromStmt(
 ) ExecutionBuilder {
 	switch s := stmt.(type) {
 	case *ast.LabeledStmt:
-		return executionFromStmt(builder, scopes, fset, s.Stmt, returnOutputs)
+		builder = builder.ConnectTo(builder.LabelPoint(s.Label.Name))
+		return executionFromBranchTargetStmt(builder, scopes, fset, s.Stmt, s.Label.Name, returnOutputs)
+	case *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+		return executionFromBranchTargetStmt(builder, scopes, fset, stmt, "", returnOutputs)
+	case *ast.BranchStmt:
+		label := ""
+		if s.Label != nil {
+			label = s.Label.Name
+		}
+		var ok bool
+		switch s.Tok {
+		case token.BREAK:
+			builder, ok = builder.Break(label)
+			assertSupported(ok, s, "break target not found: label='%v'", label)
+			return builder
+		case token.CONTINUE:
+			builder, ok = builder.Continue(label)
+			assertSupported(ok, s, "continue target not found: label='%v'", label)
+			return builder
+		case token.GOTO:
+			return builder.Goto(label)
+		}
+		return builder
 	case *ast.BlockStmt:
 		return executionFromStmtList(builder, scopes, fset, s.List, returnOutputs)
 	case *ast.IfStmt:
@@ -235,44 +318,19 @@ func executionFromStmt(
 			}
 			executionFromStmt(builder, scopes.PushScope(), fset, bodies[i], returnOutputs).ConnectTo(afterIf.CurrentPoint)
 		}
-		return afterIf
-	case *ast.ForStmt:
-		scopes = scopes.PushScope()
-		if s.Init != nil {
-			builder = executionFromStmt(builder, scopes, fset, s.Init, returnOutputs)
+		// chain without final else skips all bodies when none of the conditions holds
+		last := s
+		for nested, ok := last.Else.(*ast.IfStmt); ok; nested, ok = last.Else.(*ast.IfStmt) {
+			last = nested
 		}
-		beforeFor := builder
-
-		builder = executionFromStmt(builder, scopes, fset, s.Body, returnOutputs)
-		if s.Post != nil {
-			builder = executionFromStmt(builder, scopes, fset, s.Post, returnOutputs)
+		if last.Else == nil {
+			builder.ConnectTo(afterIf.CurrentPoint)
 		}
-		builder, _ = executionFromExpr(builder, scopes, fset, s.Cond, 1)
-		builder.ConnectTo(beforeFor.CurrentPoint)
-		return builder
-	case *ast.RangeStmt:
-		scopes = scopes.PushScope()
-		beforeFor := builder
-		// create key, value in scope and reset them in IR
-		if s.Key != nil {
-			builder = builder.ApplyNextWithRef(AssignSelectorOp{
-				ToSelector:   VarSelector{VarId: scopes.CreateVar(s.Key.(*ast.Ident).Name)},
-				FromSelector: VarSelector{VarId: BlankVarId},
-			}, s.Key.Pos())
-		}
-		if s.Value != nil {
-			builder = builder.ApplyNextWithRef(AssignSelectorOp{
-				ToSelector:   VarSelector{VarId: scopes.CreateVar(s.Value.(*ast.Ident).Name)},
-				FromSelector: VarSelector{VarId: BlankVarId},
-			}, s.Value.Pos())
-		}
-		builder = executionFromStmt(builder, scopes, fset, s.Body, returnOutputs)
-		builder.ConnectTo(beforeFor.CurrentPoint)
-		return builder
+		return afterIf
 	case *ast.DeclStmt, *ast.AssignStmt:
 		names, values, isDecl := deconstructDecl(stmt)
 		if isDecl {
-			utils.Assertf(len(values) == 1 || len(values) == len(names), "decl initial inputs/outputs count mismatch: %v", fset.Position(s.Pos()))
+			assertSupported(len(values) == 1 || len(values) == len(names), s, "decl inputs/outputs count mismatch")
 			valueOutputs := len(names)
 			if len(values) == len(names) {
 				valueOutputs = 1
@@ -282,7 +340,7 @@ func executionFromStmt(
 			for _, value := range values {
 				var valueVarComposition []VarComposition
 				builder, valueVarComposition = executionFromExpr(builder, scopes, fset, value, valueOutputs)
-				utils.Assertf(len(valueVarComposition) == valueOutputs, "expression must have %v outputs: %v", valueOutputs, fset.Position(s.Pos()))
+				assertSupported(len(valueVarComposition) == valueOutputs, value, "expression must have %v outputs", valueOutputs)
 				varCompositions = append(varCompositions, valueVarComposition...)
 			}
 			for i, name := range names {
@@ -290,7 +348,7 @@ func executionFromStmt(
 				builder = executionFromVarComposition(fset, s, builder, VarSelector{VarId: varId}, varCompositions[i])
 			}
 		} else if assign, ok := stmt.(*ast.AssignStmt); ok {
-			utils.Assertf(len(assign.Rhs) == 1 || len(assign.Lhs) == len(assign.Rhs), "assign initial inputs/outputs count mismatch: %v", fset.Position(s.Pos()))
+			assertSupported(len(assign.Rhs) == 1 || len(assign.Lhs) == len(assign.Rhs), s, "assign inputs/outputs count mismatch")
 			valueOutputs := len(assign.Lhs)
 			if len(assign.Lhs) == len(assign.Rhs) {
 				valueOutputs = 1
@@ -302,11 +360,11 @@ func executionFromStmt(
 				builder, valueVarComposition = executionFromExpr(builder, scopes, fset, value, valueOutputs)
 				varCompositions = append(varCompositions, valueVarComposition...)
 			}
-			utils.Assertf(len(varCompositions) == len(assign.Lhs), "assign final inputs/outputs count mismatch: %v (%v != %v)", fset.Position(s.Pos()), len(varCompositions), len(assign.Lhs))
+			assertSupported(len(varCompositions) == len(assign.Lhs), s, "assign inputs/outputs count mismatch (%v != %v)", len(varCompositions), len(assign.Lhs))
 			for i := range assign.Lhs {
 				var lhsVarComposition []VarComposition
 				builder, lhsVarComposition = executionFromExpr(builder, scopes, fset, assign.Lhs[i], 1)
-				utils.Assertf(len(lhsVarComposition) == 1, "lhs should have single value: %v", fset.Position(s.Pos()))
+				assertSupported(len(lhsVarComposition) == 1, assign.Lhs[i], "lhs must have single value")
 				// there can be more complex lhs which will be hard to analyze:
 				// func f(a, b, c T) *T { return &b }
 				// f(a, b, c).x.y = 1
@@ -320,36 +378,18 @@ func executionFromStmt(
 			}
 		}
 		return builder
-	case *ast.SwitchStmt:
-		scopes = scopes.PushScope()
-		builder = executionFromStmt(builder, scopes, fset, s.Init, returnOutputs)
-		afterSwitch := builder.AcquirePointWithRef(s.End())
-		for _, clause := range s.Body.List {
-			caseClause := clause.(*ast.CaseClause)
-			executionFromStmtList(builder, scopes.PushScope(), fset, caseClause.Body, returnOutputs).ConnectTo(afterSwitch.CurrentPoint)
-		}
-		return afterSwitch
-	case *ast.TypeSwitchStmt:
-		scopes = scopes.PushScope()
-		builder = executionFromStmt(builder, scopes, fset, s.Init, returnOutputs)
-		if assign, ok := s.Assign.(*ast.AssignStmt); ok && assign.Tok == token.DEFINE {
-			utils.Assertf(len(assign.Lhs) == 1, "type switch assignment must have single variable")
-			scopes.CreateVar(assign.Lhs[0].(*ast.Ident).Name)
-		}
-		afterSwitch := builder.AcquirePointWithRef(s.End())
-		for _, clause := range s.Body.List {
-			caseClause := clause.(*ast.CaseClause)
-			executionFromStmtList(builder, scopes.PushScope(), fset, caseClause.Body, returnOutputs).ConnectTo(afterSwitch.CurrentPoint)
-		}
-		return afterSwitch
 	case *ast.ReturnStmt:
-		utils.Assertf(len(s.Results) == 0 || len(s.Results) == 1 || len(s.Results) == returnOutputs, "return inputs/outputs count mismatch: %v", fset.Position(s.Pos()))
+		assertSupported(len(s.Results) == 0 || len(s.Results) == 1 || len(s.Results) == returnOutputs, s, "return inputs/outputs count mismatch")
+		outputs, inlined := builder.InlinedCallOutputs()
 		// naked return case
 		if len(s.Results) == 0 {
-			return builder.ApplyNextWithRef(ReturnVarsOp{VarIds: nil}, s.Pos())
+			if inlined { // named results of the inlined function literal are its outputs already
+				return builder.Return()
+			}
+			return builder.ApplyNextWithRef(ReturnVarsOp{VarIds: nil}, s.Pos()).Exit()
 		}
 		resultOutputs := 1
-		if len(s.Results) > 1 && returnOutputs == 1 {
+		if len(s.Results) == 1 && returnOutputs > 1 { // return g() where g has multiple results
 			resultOutputs = returnOutputs
 		}
 		var varCompositions []VarComposition
@@ -358,53 +398,383 @@ func executionFromStmt(
 			builder, resultVarComposition = executionFromExpr(builder, scopes, fset, result, resultOutputs)
 			varCompositions = append(varCompositions, resultVarComposition...)
 		}
+		if inlined {
+			for i := range varCompositions {
+				if i < len(outputs) {
+					builder = executionFromVarComposition(fset, s, builder, VarSelector{VarId: outputs[i]}, varCompositions[i])
+				}
+			}
+			return builder.Return()
+		}
 		varIds := make([]VarId, 0, len(s.Results))
 		for i := range varCompositions {
 			varId := scopes.NewVarId()
 			varIds = append(varIds, varId)
 			builder = executionFromVarComposition(fset, s, builder, VarSelector{VarId: varId}, varCompositions[i])
 		}
-		return builder.ApplyNextWithRef(ReturnVarsOp{VarIds: varIds}, s.Pos())
+		return builder.ApplyNextWithRef(ReturnVarsOp{VarIds: varIds}, s.Pos()).Exit()
+	case *ast.SendStmt:
+		builder, _ = executionFromExpr(builder, scopes, fset, s.Value, 1)
+		return builder
 	case *ast.ExprStmt:
 		builder, _ = executionFromExpr(builder, scopes, fset, s.X, 0)
+		if call, ok := s.X.(*ast.CallExpr); ok && scopes.IsNoReturnCall(call) {
+			return builder.Exit()
+		}
 		return builder
+	case *ast.GoStmt:
+		// function literal of the goroutine is executed concurrently with the caller, so it is analyzed separately instead of inlining
+		return executionFromDelayedCall(builder, scopes, fset, s.Call)
+	case *ast.DeferStmt:
+		// deferred function literal is executed at the function exit, so it is analyzed separately instead of inlining
+		return executionFromDelayedCall(builder, scopes, fset, s.Call)
 	case
 		nil,
-		*ast.BranchStmt, /* break / continue / goto */
-		*ast.DeferStmt,
 		*ast.EmptyStmt,
-		*ast.GoStmt,
-		*ast.IncDecStmt,
-		*ast.SelectStmt,
-		*ast.SendStmt:
+		*ast.IncDecStmt:
 		return builder
 	}
-	panic(fmt.Errorf("unexpected statement found: %T (%+v)", stmt, stmt))
+	unsupported(stmt, "unexpected statement %T", stmt)
+	return builder
 }
 
+// executionFromCaseClauses connects clauses of switch or type switch statement:
+// case expressions are evaluated in the source order and default clause (or the end of the switch if there is no default) is taken after all of them
+// fallthrough at the end of the clause body passes control to the next clause body
+func executionFromCaseClauses(
+	builder ExecutionBuilder,
+	scopes Scopes,
+	fset *token.FileSet,
+	switchBody *ast.BlockStmt,
+	label string,
+	afterSwitch ExecutionBuilder,
+	evaluateCases bool,
+	bind func(builder ExecutionBuilder, scopes Scopes, clause *ast.CaseClause) ExecutionBuilder,
+	returnOutputs int,
+) ExecutionBuilder {
+	bodies := make([]ExecutionBuilder, len(switchBody.List))
+	for i := range switchBody.List {
+		bodies[i] = builder.AcquirePoint()
+	}
+	noMatch := afterSwitch
+	for i, clause := range switchBody.List {
+		caseClause := clause.(*ast.CaseClause)
+		if caseClause.List == nil {
+			noMatch = bodies[i]
+			continue
+		}
+		if evaluateCases {
+			for _, caseExpr := range caseClause.List {
+				builder, _ = executionFromExpr(builder, scopes, fset, caseExpr, 1)
+			}
+		}
+		builder.ConnectTo(bodies[i].CurrentPoint)
+	}
+	builder.ConnectTo(noMatch.CurrentPoint)
+
+	for i, clause := range switchBody.List {
+		caseClause := clause.(*ast.CaseClause)
+		clauseScopes := scopes.PushScope()
+		body := bodies[i].PushBranchTarget(label, afterSwitch.CurrentPoint, nil)
+		body = bind(body, clauseScopes, caseClause)
+
+		stmts, next := caseClause.Body, afterSwitch
+		if len(stmts) > 0 {
+			if branch, ok := stmts[len(stmts)-1].(*ast.BranchStmt); ok && branch.Tok == token.FALLTHROUGH && i+1 < len(bodies) {
+				stmts, next = stmts[:len(stmts)-1], bodies[i+1]
+			}
+		}
+		executionFromStmtList(body, clauseScopes, fset, stmts, returnOutputs).ConnectTo(next.CurrentPoint)
+	}
+	return afterSwitch
+}
+
+// executionFromBranchTargetStmt builds statements which can be targeted by break / continue (loops, switch and select)
+// label is the name of the enclosing *ast.LabeledStmt or empty string
+func executionFromBranchTargetStmt(
+	builder ExecutionBuilder,
+	scopes Scopes,
+	fset *token.FileSet,
+	stmt ast.Stmt,
+	label string,
+	returnOutputs int,
+) ExecutionBuilder {
+	switch s := stmt.(type) {
+	case *ast.ForStmt:
+		// init -> header -> cond -> body -> post -> header
+		//                    |
+		//                    +-> after
+		scopes = scopes.PushScope()
+		if s.Init != nil {
+			builder = executionFromStmt(builder, scopes, fset, s.Init, returnOutputs)
+		}
+		header := builder.ConnectTo(builder.AcquirePointWithRef(s.Pos()).CurrentPoint)
+		afterFor := builder.AcquirePointWithRef(s.End())
+		beforePost := builder.AcquirePoint()
+
+		body, _ := executionFromExpr(header, scopes, fset, s.Cond, 1)
+		if s.Cond != nil { // for { ... } can be exited only with break
+			body.ConnectTo(afterFor.CurrentPoint)
+		}
+		body = body.PushBranchTarget(label, afterFor.CurrentPoint, &beforePost.CurrentPoint)
+		executionFromStmt(body, scopes.PushScope(), fset, s.Body, returnOutputs).ConnectTo(beforePost.CurrentPoint)
+		builder = beforePost
+		if s.Post != nil {
+			builder = executionFromStmt(builder, scopes, fset, s.Post, returnOutputs)
+		}
+		builder.ConnectTo(header.CurrentPoint)
+		return afterFor
+	case *ast.RangeStmt:
+		// range expression -> header -> key, value -> body -> header
+		//                       |
+		//                       +-> after
+		// range over slices, maps, channels, integers and functions share the same structure
+		builder, _ = executionFromExpr(builder, scopes, fset, s.X, 1)
+		scopes = scopes.PushScope()
+		header := builder.ConnectTo(builder.AcquirePointWithRef(s.Pos()).CurrentPoint)
+		afterFor := builder.AcquirePointWithRef(s.End())
+		header.ConnectTo(afterFor.CurrentPoint)
+
+		body := header.PushBranchTarget(label, afterFor.CurrentPoint, &header.CurrentPoint)
+		// key & value get new values on every iteration
+		for _, rangeVar := range []ast.Expr{s.Key, s.Value} {
+			if rangeVar == nil {
+				continue
+			}
+			if ident, ok := rangeVar.(*ast.Ident); ok && s.Tok == token.DEFINE {
+				body = body.ApplyNextWithRef(AssignSelectorOp{
+					ToSelector:   VarSelector{VarId: scopes.CreateVar(ident)},
+					FromSelector: VarSelector{VarId: BlankVarId},
+				}, rangeVar.Pos())
+				continue
+			}
+			var varCompositions []VarComposition
+			body, varCompositions = executionFromExpr(body, scopes, fset, rangeVar, 1)
+			if len(varCompositions[0]) == 1 && len(varCompositions[0][0].Path) == 0 {
+				body = executionFromVarComposition(fset, rangeVar, body, varCompositions[0][0].VarSelector, nil)
+			}
+		}
+		executionFromStmt(body, scopes.PushScope(), fset, s.Body, returnOutputs).ConnectTo(header.CurrentPoint)
+		return afterFor
+	case *ast.SwitchStmt:
+		scopes = scopes.PushScope()
+		builder = executionFromStmt(builder, scopes, fset, s.Init, returnOutputs)
+		builder, _ = executionFromExpr(builder, scopes, fset, s.Tag, 1)
+		afterSwitch := builder.AcquirePointWithRef(s.End())
+		noBinding := func(builder ExecutionBuilder, _ Scopes, _ *ast.CaseClause) ExecutionBuilder { return builder }
+		return executionFromCaseClauses(builder, scopes, fset, s.Body, label, afterSwitch, true, noBinding, returnOutputs)
+	case *ast.TypeSwitchStmt:
+		scopes = scopes.PushScope()
+		builder = executionFromStmt(builder, scopes, fset, s.Init, returnOutputs)
+		// switch x.(type) { ... } or switch v := x.(type) { ... }
+		var symbol *ast.Ident
+		var typeAssert *ast.TypeAssertExpr
+		switch assign := s.Assign.(type) {
+		case *ast.AssignStmt:
+			assertSupported(len(assign.Lhs) == 1 && len(assign.Rhs) == 1, assign, "type switch assignment must have single variable")
+			symbol, typeAssert = assign.Lhs[0].(*ast.Ident), assign.Rhs[0].(*ast.TypeAssertExpr)
+		case *ast.ExprStmt:
+			typeAssert = assign.X.(*ast.TypeAssertExpr)
+		}
+		var varCompositions []VarComposition
+		builder, varCompositions = executionFromExpr(builder, scopes, fset, typeAssert.X, 1)
+		afterSwitch := builder.AcquirePointWithRef(s.End())
+		// every clause declares its own symbolic variable which shares all components with the switch expression
+		bindSymbol := func(builder ExecutionBuilder, scopes Scopes, clause *ast.CaseClause) ExecutionBuilder {
+			if symbol == nil {
+				return builder
+			}
+			varId := scopes.CreateImplicitVar(symbol, clause)
+			return executionFromVarComposition(fset, clause, builder, VarSelector{VarId: varId}, varCompositions[0])
+		}
+		return executionFromCaseClauses(builder, scopes, fset, s.Body, label, afterSwitch, false, bindSymbol, returnOutputs)
+	case *ast.SelectStmt:
+		if len(s.Body.List) == 0 { // select {} blocks forever
+			return builder.AcquirePoint()
+		}
+		afterSelect := builder.AcquirePointWithRef(s.End())
+		for _, clause := range s.Body.List {
+			commClause := clause.(*ast.CommClause)
+			clauseScopes := scopes.PushScope()
+			body := builder.PushBranchTarget(label, afterSelect.CurrentPoint, nil)
+			body = executionFromStmt(body, clauseScopes, fset, commClause.Comm, returnOutputs)
+			executionFromStmtList(body, clauseScopes, fset, commClause.Body, returnOutputs).ConnectTo(afterSelect.CurrentPoint)
+		}
+		return afterSelect
+	}
+	return executionFromStmt(builder, scopes, fset, stmt, returnOutputs)
+}
+
+// createFieldVars creates variables for the function parameters (or results) in the order of their appearance
+// Unnamed fields are represented with BlankVarId
+func createFieldVars(scopes Scopes, fields *ast.FieldList) []VarId {
+	var varIds []VarId
+	if fields == nil {
+		return varIds
+	}
+	for _, field := range fields.List {
+		if len(field.Names) == 0 {
+			varIds = append(varIds, BlankVarId)
+		}
+		for _, name := range field.Names {
+			varIds = append(varIds, scopes.CreateVar(name))
+		}
+	}
+	return varIds
+}
+
+// executionFromDelayedCall evaluates function value (with the receiver of the method) and arguments of the go or defer statement in place:
+// only the call itself is delayed, so it isn't modeled
+func executionFromDelayedCall(builder ExecutionBuilder, scopes Scopes, fset *token.FileSet, call *ast.CallExpr) ExecutionBuilder {
+	builder, _ = executionFromExpr(builder, scopes, fset, call.Fun, 1)
+	for _, arg := range call.Args {
+		builder, _ = executionFromExpr(builder, scopes, fset, arg, 1)
+	}
+	return builder
+}
+
+// executionFromInlinedCall builds the body of the immediately-invoked function literal within the caller execution: func(x []int) { ... }(a)
+func executionFromInlinedCall(
+	builder ExecutionBuilder,
+	scopes Scopes,
+	fset *token.FileSet,
+	call *ast.CallExpr,
+	lit *ast.FuncLit,
+	exprOutputs int,
+) (ExecutionBuilder, []VarComposition) {
+	var argVarCompositions []VarComposition
+	for _, arg := range call.Args {
+		var argVarComposition []VarComposition
+		builder, argVarComposition = executionFromExpr(builder, scopes, fset, arg, 1)
+		argVarCompositions = append(argVarCompositions, argVarComposition...)
+	}
+	scopes = scopes.PushScope()
+	params := createFieldVars(scopes, lit.Type.Params)
+	// variadic parameter packs arguments into the fresh slice unless the slice is passed as is: f(x...)
+	if last := len(lit.Type.Params.List) - 1; last >= 0 && call.Ellipsis == token.NoPos {
+		if _, variadic := lit.Type.Params.List[last].Type.(*ast.Ellipsis); variadic {
+			packed := VarComposition{{VarSelector: VarSelector{VarId: BlankVarId}}}
+			argVarCompositions = append(argVarCompositions[:min(len(params)-1, len(argVarCompositions))], packed)
+		}
+	}
+	for i, varId := range params {
+		if i < len(argVarCompositions) {
+			builder = executionFromVarComposition(fset, call, builder, VarSelector{VarId: varId}, argVarCompositions[i])
+		}
+	}
+	outputs := createFieldVars(scopes, lit.Type.Results)
+	for i := range outputs {
+		if outputs[i] == BlankVarId {
+			outputs[i] = scopes.NewVarId()
+		}
+	}
+	afterCall := builder.AcquirePointWithRef(call.End())
+	body := builder.InlineCall(afterCall.CurrentPoint, outputs)
+	executionFromStmt(body, scopes, fset, lit.Body, len(outputs)).ConnectTo(afterCall.CurrentPoint)
+
+	results := make([]VarComposition, exprOutputs)
+	for i := range results {
+		varId := VarId(BlankVarId)
+		if i < len(outputs) {
+			varId = outputs[i]
+		}
+		results[i] = VarComposition{{VarSelector: VarSelector{VarId: varId}}}
+	}
+	return afterCall, results
+}
+
+// UnsupportedError reports the construct which can't be converted to the execution
+type UnsupportedError struct {
+	Pos token.Pos
+	Err error
+}
+
+func (e *UnsupportedError) Error() string { return fmt.Sprintf("unsupported construct: %v", e.Err) }
+
+func (e *UnsupportedError) Unwrap() error { return e.Err }
+
+// unsupported aborts building of the execution: panic is recovered by recoverUnsupported at the ExecutionFromFunc level
+func unsupported(node ast.Node, format string, args ...any) {
+	panic(&UnsupportedError{Pos: node.Pos(), Err: fmt.Errorf(format, args...)})
+}
+
+func assertSupported(condition bool, node ast.Node, format string, args ...any) {
+	if !condition {
+		unsupported(node, format, args...)
+	}
+}
+
+// recoverUnsupported converts panic raised by unsupported to the error and propagates all other panics
+func recoverUnsupported(err *error) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	if unsupportedErr, ok := recovered.(*UnsupportedError); ok {
+		*err = unsupportedErr
+		return
+	}
+	panic(recovered)
+}
+
+// ExecutionFromFunc builds execution of the function declaration
+// *UnsupportedError is returned if the function contains construct which can't be analyzed
 func ExecutionFromFunc(
 	scopes Scopes,
 	fset *token.FileSet,
 	funcDecl *ast.FuncDecl,
+) (execution Execution, err error) {
+	defer recoverUnsupported(&err)
+	return executionFromFuncBody(scopes, fset, funcDecl.Pos(), funcDecl.Recv, funcDecl.Type, funcDecl.Body), nil
+}
+
+// MustExecutionFromFunc is ExecutionFromFunc which panics on the unsupported constructs
+func MustExecutionFromFunc(scopes Scopes, fset *token.FileSet, funcDecl *ast.FuncDecl) Execution {
+	execution, err := ExecutionFromFunc(scopes, fset, funcDecl)
+	utils.Assertf(err == nil, "unable to build execution: %v: %v", fset.Position(funcDecl.Pos()), err)
+	return execution
+}
+
+// ExecutionFromFuncLit builds separate execution of the function literal
+// Captured variables are resolved through the scopes of the enclosing function, so they share VarId with the enclosing execution
+func ExecutionFromFuncLit(
+	scopes Scopes,
+	fset *token.FileSet,
+	funcLit *ast.FuncLit,
+) (execution Execution, err error) {
+	defer recoverUnsupported(&err)
+	return executionFromFuncBody(scopes, fset, funcLit.Pos(), nil, funcLit.Type, funcLit.Body), nil
+}
+
+// executionFromFuncBody builds execution of the function with optional receiver (recv is nil for functions and function literals)
+func executionFromFuncBody(
+	scopes Scopes,
+	fset *token.FileSet,
+	pos token.Pos,
+	recv *ast.FieldList,
+	funcType *ast.FuncType,
+	body *ast.BlockStmt,
 ) Execution {
 	builder := NewExecutionBuilder(fset)
-	builder.AssignRef(builder.CurrentPoint, funcDecl.Pos())
+	builder.AssignRef(builder.CurrentPoint, pos)
 
 	scopes = scopes.PushScope()
-	if funcDecl.Type.Params != nil {
-		for _, params := range funcDecl.Type.Params.List {
-			for _, name := range params.Names {
-				scopes.CreateVar(name.Name)
-			}
-		}
-	}
-	if funcDecl.Type.Results != nil {
-		for _, params := range funcDecl.Type.Results.List {
-			for _, name := range params.Names {
-				scopes.CreateVar(name.Name)
-			}
+	receivers := createFieldVars(scopes, recv)
+	params := createFieldVars(scopes, funcType.Params)
+	results := createFieldVars(scopes, funcType.Results)
+	exit := builder.AcquirePointWithRef(body.Rbrace)
+	builder = builder.WithExitPoint(exit.CurrentPoint)
+	builder = executionFromStmt(builder, scopes, fset, body, funcType.Results.NumFields())
+	builder.ConnectTo(exit.CurrentPoint)
+
+	execution := builder.Build()
+	execution.Params = append(receivers, params...)
+	execution.Results = results
+	execution.ExitPoint = exit.CurrentPoint
+	if recv != nil && len(recv.List) == 1 {
+		if _, isPointer := ast.Unparen(recv.List[0].Type).(*ast.StarExpr); isPointer && receivers[0] != BlankVarId {
+			execution.OutParams = append(execution.OutParams, receivers[0])
 		}
 	}
-	builder = executionFromStmt(builder, scopes, fset, funcDecl.Body, funcDecl.Type.Results.NumFields())
-	return builder.Build()
+	return execution
 }
@@ -91,7 +91,7 @@ func TestSelectorFunc(t *testing.T) {
 	fset, funcDecl := utils.MustGenFunc(`func f(u *User) {
 		u.Name = g()
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 		"g":            -3,
@@ -110,7 +110,7 @@ func TestSelectors(t *testing.T) {
 	}
 	return user.Meta.Phone
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -123,7 +123,7 @@ func TestMultiAssignment(t *testing.T) {
 	a, b = f2()
 	return a + b
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -136,7 +136,7 @@ func TestNakedReturn(t *testing.T) {
 	b = "hi"
 	return
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -156,7 +156,7 @@ func TestBlocks(t *testing.T) {
 	}
 	return result
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -173,7 +173,7 @@ func TestGqlgenBug(t *testing.T) {
 	}
 	return ret
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -185,7 +185,7 @@ func TestArrayLiteral(t *testing.T) {
 	x := append([]string{}, []string{}...)
 	return x
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -251,7 +251,7 @@ func TestTheinegoBug(t *testing.T) {
 		}
 	}
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -275,10 +275,156 @@ func TestName(t *testing.T) {
 	}
 	return VarEmbed{}, false
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
 	ValidateExecution(DefaultFuncSpecCollection, execution)
 	t.Logf("%v", execution)
 }
+
+// reachableLines returns source lines of all execution points reachable from the root
+func reachableLines(execution Execution) map[int]bool {
+	lines := make(map[int]bool)
+	next := func(point ExecutionPoint) []ExecutionPoint {
+		points := make([]ExecutionPoint, 0)
+		for _, transition := range execution.Transitions[point] {
+			points = append(points, transition.ToPoint)
+		}
+		return points
+	}
+	for _, point := range TopologyOrder(execution.RootPoint, next) {
+		if pos, ok := execution.SourceCodeReferences.References[point]; ok {
+			lines[execution.SourceCodeReferences.Fset.Position(pos).Line] = true
+		}
+	}
+	return lines
+}
+
+func TestBranchStmt(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(x []int) {
+	for {
+		x = append(x, 1)
+		break
+		x = append(x, 2)
+	}
+	goto end
+	x = append(x, 3)
+end:
+	x = append(x, 4)
+	switch {
+	case len(x) > 0:
+		break
+		x = append(x, 5)
+	}
+	x = append(x, 6)
+outer:
+	for _, y := range x {
+		for {
+			x = append(x, y)
+			continue outer
+		}
+		x = append(x, 7)
+	}
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	lines := reachableLines(execution)
+	require.True(t, lines[4])
+	require.False(t, lines[6])
+	require.False(t, lines[9])
+	require.True(t, lines[11])
+	require.False(t, lines[15])
+	require.True(t, lines[17])
+	require.True(t, lines[21])
+	require.False(t, lines[24])
+}
+
+func TestBranchStmtWarnings(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int) []int {
+	var a, b []int
+	for {
+		a = append(prefix, 1)
+		break
+		b = append(prefix, 2)
+	}
+	return append(a, b...)
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+}
+
+func TestLoopStructure(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(x []int, ok bool) {
+	for len(x) < 10 {
+		x = append(x, 1)
+	}
+	x = append(x, 2)
+	for {
+		if ok {
+			break
+		}
+	}
+	x = append(x, 3)
+	for {
+		x = append(x, 4)
+	}
+	x = append(x, 5)
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	lines := reachableLines(execution)
+	require.True(t, lines[4])
+	require.True(t, lines[6])
+	require.True(t, lines[12])
+	require.True(t, lines[14])
+	require.False(t, lines[16])
+}
+
+func TestSelectStructure(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(x []int, ch chan int) {
+	select {
+	case v := <-ch:
+		x = append(x, v)
+	}
+	x = append(x, 1)
+	select {}
+	x = append(x, 2)
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	lines := reachableLines(execution)
+	require.True(t, lines[5])
+	require.True(t, lines[7])
+	require.False(t, lines[9])
+}
+
+func TestUnsupportedConstruct(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(x []int) {
+	x = append(x, 1)
+	x
+}`)
+	_, err := ExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	var unsupportedErr *UnsupportedError
+	require.ErrorAs(t, err, &unsupportedErr)
+	require.Equal(t, 4, fset.Position(unsupportedErr.Pos).Line)
+	require.EqualError(t, err, "unsupported construct: identifier must be used as single value")
+
+	funcDecl.Body.List = funcDecl.Body.List[:1]
+	funcDecl.Body.List = append(funcDecl.Body.List, &ast.BadStmt{From: funcDecl.Body.Rbrace, To: funcDecl.Body.Rbrace})
+	_, err = ExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	require.EqualError(t, err, "unsupported construct: unexpected statement *ast.BadStmt")
+
+	fset, funcDecl = utils.MustGenFunc(`func f(x []int) []int { return x[0:1:2] }`)
+	_, err = ExecutionFromFunc(NewScopes(map[string]FuncId{}), fset, funcDecl)
+	require.EqualError(t, err, "unsupported construct: func $Slice is not registered in the scope")
+
+	// branch targets are checked only by the type checker, so untyped frontend can meet the dangling break
+	fset, funcDecl = utils.MustGenFunc(`func f() {
+	break
+}`)
+	_, err = ExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	require.ErrorAs(t, err, &unsupportedErr)
+	require.Equal(t, 3, fset.Position(unsupportedErr.Pos).Line)
+	require.EqualError(t, err, "unsupported construct: break target not found: label=''")
+}
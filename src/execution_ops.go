@@ -49,6 +49,17 @@ func (p VarEmbed) Select(name string) (VarEmbed, bool) {
 	return VarEmbed{}, false
 }
 
+// SelectPath applies Select for every name of the path
+func (p VarEmbed) SelectPath(path Path) (VarEmbed, bool) {
+	for _, name := range path {
+		var ok bool
+		if p, ok = p.Select(name); !ok {
+			return VarEmbed{}, false
+		}
+	}
+	return p, p.VarSelector.VarId != BlankVarId
+}
+
 // BlankVarId is a special variable which represent universal sink & source of data
 // - If v1 = BlankVarId - then it behaves like a make() operation (so the previous value of v1 always reset with a fresh new instance)
 // - If BlankVarId = v1 - then it behaves like a /dev/null and just consumes the data
@@ -17,13 +17,19 @@ func (t ExecutionTrace) String() string {
 }
 
 func GenerateTraces(execution Execution, repeatLimit int) []ExecutionTrace {
+	return generateTraces(nil, execution, repeatLimit)
+}
+
+// generateTraces stops generation of the traces as soon as the budget is exceeded
+func generateTraces(budget *Budget, execution Execution, repeatLimit int) []ExecutionTrace {
 	visits := make(map[ExecutionPoint]int)
 	traces := make([]ExecutionTrace, 0)
-	generateTraces(execution, execution.RootPoint, repeatLimit, nil, visits, &traces)
+	generateTracesFrom(budget, execution, execution.RootPoint, repeatLimit, nil, visits, &traces)
 	return traces
 }
 
-func generateTraces(
+func generateTracesFrom(
+	budget *Budget,
 	execution Execution,
 	root ExecutionPoint,
 	repeatLimit int,
@@ -31,52 +37,92 @@ func generateTraces(
 	visits map[ExecutionPoint]int,
 	traces *[]ExecutionTrace,
 ) {
+	if budget.step() {
+		return
+	}
 	finish := true
 	for _, transition := range execution.Transitions[root] {
 		if visits[transition.ToPoint] >= repeatLimit {
 			continue
 		}
 		visits[transition.ToPoint]++
-		generateTraces(execution, transition.ToPoint, repeatLimit, append(current, transition), visits, traces)
+		generateTracesFrom(budget, execution, transition.ToPoint, repeatLimit, append(current, transition), visits, traces)
 		visits[transition.ToPoint]--
 		finish = false
 	}
-	if finish {
+	if finish && !budget.traces(len(*traces)) {
 		*traces = append(*traces, slices.Clone(current))
 	}
 }
 
+// ValidationWarning points to the execution point where append can overwrite elements of the shared slice
+// Trace leading to the overwrite (it ends at the ExecutionPoint) and ConflictPoint of the earlier append which result is overwritten are set only by ValidateExecutionTraces
 type ValidationWarning struct {
 	Trace          ExecutionTrace
 	ExecutionPoint ExecutionPoint
+	ConflictPoint  ExecutionPoint
 }
 
+// ValidateExecution finds potential append overwrites with the dataflow analysis of the simplified execution (see ValidateDataflow)
 func ValidateExecution(funcs map[FuncId]FuncSpec, execution Execution) []ValidationWarning {
-	simplified, simplifiedToOriginal := SimplifyExecution(SimplificationContext{Funcs: funcs}, execution)
-	traces := GenerateTraces(simplified, 2)
+	warnings, _ := ValidateExecutionWithin(nil, funcs, execution)
+	return warnings
+}
+
+// ValidateExecutionWithin is ValidateExecution bounded by the budget
+// If the budget is exceeded, error wrapping ErrBudgetExceeded is returned together with the warnings found before that
+// Internal failure of the analysis is returned as the error wrapping ErrInternal
+func ValidateExecutionWithin(budget *Budget, funcs map[FuncId]FuncSpec, execution Execution) (warnings []ValidationWarning, err error) {
+	defer recoverInternal(&err)
+	simplified, simplifiedToOriginal := SimplifyExecution(SimplificationContext{Funcs: funcs, Budget: budget}, execution)
+	if err := budget.Err(); err != nil {
+		return nil, err
+	}
+	for _, point := range ValidateDataflow(budget, simplified) {
+		warnings = append(warnings, ValidationWarning{ExecutionPoint: simplifiedToOriginal[point]})
+	}
+	return warnings, budget.Err()
+}
+
+// ValidateExecutionTraces finds potential append overwrites by validation of every execution trace which visits every point at most twice
+// Number of traces is exponential in the number of branches, so it is used only as a reference for ValidateExecution and to explain its warnings
+// Points of the returned traces are mapped back to the points of the original execution (while operations are the simplified ones)
+func ValidateExecutionTraces(budget *Budget, funcs map[FuncId]FuncSpec, execution Execution) (warnings []ValidationWarning, err error) {
+	defer recoverInternal(&err)
+	simplified, simplifiedToOriginal := SimplifyExecution(SimplificationContext{Funcs: funcs, Budget: budget}, execution)
+	if err := budget.Err(); err != nil {
+		return nil, err
+	}
+	traces := generateTraces(budget, simplified, 2)
 	warnedExecutionPoints := make(map[ExecutionPoint]struct{})
-	var warnings []ValidationWarning
 	for _, trace := range traces {
 		for _, warning := range ValidateTrace(trace) {
 			if _, ok := warnedExecutionPoints[warning.ExecutionPoint]; ok {
 				continue
 			}
 			warnedExecutionPoints[warning.ExecutionPoint] = struct{}{}
+			originalTrace := make(ExecutionTrace, len(warning.Trace))
+			for i, transition := range warning.Trace {
+				originalTrace[i] = ExecutionTransition{ToPoint: simplifiedToOriginal[transition.ToPoint], Operation: transition.Operation}
+			}
 			warnings = append(warnings, ValidationWarning{
-				Trace:          trace,
+				Trace:          originalTrace,
 				ExecutionPoint: simplifiedToOriginal[warning.ExecutionPoint],
+				ConflictPoint:  simplifiedToOriginal[warning.ConflictPoint],
 			})
 		}
 	}
-	return warnings
+	return warnings, budget.Err()
 }
 
 func ValidateTrace(trace ExecutionTrace) []ValidationWarning {
 	originLatestGen := make(map[int]int, 0)
+	// originLatestPoint holds the point where the latest generation of the origin was produced
+	originLatestPoint := make(map[int]ExecutionPoint)
 	variableGen := make(map[VarId]VarGen)
 	warnings := make([]ValidationWarning, 0)
 	valueId := 0
-	for _, transition := range trace {
+	for i, transition := range trace {
 		switch statement := transition.Operation.(type) {
 		case AssignVarOp:
 			if statement.ToVarId == BlankVarId {
@@ -101,12 +147,14 @@ func ValidateTrace(trace ExecutionTrace) []ValidationWarning {
 			latestGen, ok := originLatestGen[targetGen.Id]
 			if ok && statement.GenChange == NextGen && targetGen.Gen <= latestGen {
 				err := ValidationWarning{
-					Trace:          trace,
+					Trace:          trace[:i+1],
 					ExecutionPoint: transition.ToPoint,
+					ConflictPoint:  originLatestPoint[targetGen.Id],
 				}
 				warnings = append(warnings, err)
 			} else if !ok || targetGen.Gen > latestGen {
 				originLatestGen[targetGen.Id] = targetGen.Gen
+				originLatestPoint[targetGen.Id] = transition.ToPoint
 			}
 			variableGen[statement.ToVarId] = targetGen
 		case NoOp:
@@ -1,6 +1,8 @@
 package src
 
 import (
+	"maps"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -18,7 +20,7 @@ func TestExecutionTrace(t *testing.T) {
 	}
 	return ret
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -51,7 +53,7 @@ func TestExecutionTrace2(t *testing.T) {
 	return
 }
 `)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -66,7 +68,7 @@ func TestExecutionTrace4(t *testing.T) {
 	}
 }
 `)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -83,7 +85,7 @@ func TestExecutionTrace3(t *testing.T) {
 	}
 }
 `)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -104,7 +106,7 @@ func TestExecutionTrace5(t *testing.T) {
 	return assigns
 }
 `)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -130,7 +132,7 @@ func TestKek(t *testing.T) {
 		send = append(send, evicted)
 	}
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -153,3 +155,590 @@ func TestKek(t *testing.T) {
 	}
 	require.Empty(t, warnings)
 }
+
+func TestTypedShadowedAppend(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+func f(append func([]int, int) []int, prefix []int) ([]int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2)
+	return a, b
+}
+func g(prefix []int) ([]int, []int) {
+	a := append(prefix, 1)
+	b := append(prefix, 2)
+	return a, b
+}`)
+	execution := MustExecutionFromFunc(NewTypedScopes(DefaultFuncs, info), fset, utils.MustExtractFunc(file, "f"))
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+	execution = MustExecutionFromFunc(NewTypedScopes(DefaultFuncs, info), fset, utils.MustExtractFunc(file, "g"))
+	require.NotEmpty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+}
+
+func TestTypedQualifiedFunc(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+import "slices"
+func Insert(s []int, i int, v ...int) []int { return s }
+func f(prefix []int) ([]int, []int, []int) {
+	a := slices.Insert(prefix, 0, 1)
+	b := Insert(prefix, 0, 2)
+	c := slices.Insert(prefix, 0, 3)
+	return a, b, c
+}`)
+	const insertFuncId FuncId = 1
+	funcs := map[string]FuncId{"slices.Insert": insertFuncId}
+	specs := FuncSpecCollection{insertFuncId: AppendFuncSpec}
+	execution := MustExecutionFromFunc(NewTypedScopes(funcs, info), fset, utils.MustExtractFunc(file, "f"))
+	warnings := ValidateExecution(specs, execution)
+	require.Len(t, warnings, 1)
+	require.Equal(t, 7, fset.Position(execution.SourceCodeReferences.References[warnings[0].ExecutionPoint]).Line)
+
+	funcs = map[string]FuncId{"main.Insert": insertFuncId}
+	execution = MustExecutionFromFunc(NewTypedScopes(funcs, info), fset, utils.MustExtractFunc(file, "f"))
+	require.Empty(t, ValidateExecution(specs, execution))
+}
+
+func TestFuncSpecMissingOutputs(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int) ([]int, []int) {
+	a, err := grow(prefix)
+	b, err := grow(prefix)
+	_ = err
+	return a, b
+}`)
+	const growFuncId FuncId = 1
+	// spec describes only the first of two results, so the second one is fresh
+	specs := FuncSpecCollection{growFuncId: MustParseFuncSpec("(buf) -> next(buf)")}
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{"grow": growFuncId}), fset, funcDecl)
+	require.Len(t, ValidateExecution(specs, execution), 1)
+}
+
+func TestCompositeLitAlias(t *testing.T) {
+	for _, body := range []string{
+		`p := Pair{Left: prefix}
+	q := Pair{Left: append(p.Left, 1)}
+	r := append(prefix, 2)
+	return q.Left, r`,
+		`p := &Pair{Left: prefix}
+	q := Pair{Left: append(p.Left, 1)}
+	r := append(prefix, 2)
+	return q.Left, r`,
+		`w := Wrapper{Pair: Pair{Left: prefix}}
+	q := append(w.Pair.Left, 1)
+	r := append(w.Pair.Left, 2)
+	return q, r`,
+	} {
+		fset, funcDecl := utils.MustGenFunc(`func f(prefix []int) ([]int, []int) {
+	` + body + `
+}`)
+		execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+		t.Logf("%v", execution)
+		require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+	}
+}
+
+func TestCompositeLitFreshFields(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int) ([]int, []int) {
+	p := Pair{Left: prefix, Right: []int{}}
+	q := append(p.Right, 1)
+	r := append(p.Left, 2)
+	return q, r
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+}
+
+func TestTypedPositionalCompositeLit(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+type Pair struct{ Left, Right []int }
+func f(prefix []int) ([]int, []int) {
+	p := Pair{nil, prefix}
+	q := append(p.Left, 1)
+	r := append(p.Right, 2)
+	s := append(prefix, 3)
+	return q, append(r, s...)
+}`)
+	execution := MustExecutionFromFunc(NewTypedScopes(DefaultFuncs, info), fset, utils.MustExtractFunc(file, "f"))
+	warnings := ValidateExecution(DefaultFuncSpecCollection, execution)
+	require.Len(t, warnings, 1)
+	require.Equal(t, 7, fset.Position(execution.SourceCodeReferences.References[warnings[0].ExecutionPoint]).Line)
+}
+
+func TestCompositeLitCallArgument(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int) ([]int, []int) {
+	a := left(Pair{Right: nil, Left: prefix})
+	b := append(a, 1)
+	c := append(prefix, 2)
+	return b, c
+}`)
+	const leftFuncId FuncId = 1
+	funcs := map[string]FuncId{AppendFuncName: AppendFuncId, "left": leftFuncId}
+	specs := FuncSpecCollection{
+		AppendFuncId: AppendFuncSpec,
+		leftFuncId: NewFuncSpec(
+			FuncMultiInput{{{VarId: 0, Selector: Path{"Left"}}}},
+			FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: 0}, GenChange: SameGen}}},
+		),
+	}
+	execution := MustExecutionFromFunc(NewScopes(funcs), fset, funcDecl)
+	require.Len(t, ValidateExecution(specs, execution), 1)
+}
+
+func TestTerminalStatements(t *testing.T) {
+	for _, guard := range []string{
+		`return x`,
+		`panic("not ok")`,
+		`os.Exit(1)`,
+		`log.Fatalf("not ok: %v", x)`,
+		`die()`,
+	} {
+		fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, ok bool) []int {
+	if !ok {
+		x := append(prefix, 1)
+		` + guard + `
+	}
+	return append(prefix, 2)
+}`)
+		scopes := NewScopes(DefaultFuncs)
+		scopes.NoReturnFuncs = maps.Clone(DefaultNoReturnFuncs)
+		scopes.NoReturnFuncs["die"] = struct{}{}
+		execution := MustExecutionFromFunc(scopes, fset, funcDecl)
+		t.Logf("%v", execution)
+		require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution), guard)
+	}
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, ok bool) []int {
+	if !ok {
+		x := append(prefix, 1)
+		println(x)
+	}
+	return append(prefix, 2)
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+}
+
+func TestEarlyReturn(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, ok bool) []int {
+	if !ok {
+		return append(prefix, 1)
+	}
+	return append(prefix, 2)
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+}
+
+func TestTypedNoReturnMethod(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+import "testing"
+func f(t *testing.T, prefix []int) []int {
+	if len(prefix) == 0 {
+		t.Fatalf("empty prefix: %v", append(prefix, 1))
+	}
+	return append(prefix, 2)
+}`)
+	execution := MustExecutionFromFunc(NewTypedScopes(DefaultFuncs, info), fset, utils.MustExtractFunc(file, "f"))
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+}
+
+func TestLoopZeroIterations(t *testing.T) {
+	for _, loop := range []string{
+		`for i := 0; i < n; i++ {
+		a = []int{i}
+	}`,
+		`for n > 0 {
+		a = []int{n}
+		n--
+	}`,
+		`for _, x := range xs {
+		a = []int{x}
+	}`,
+		`for i := range n {
+		a = []int{i}
+	}`,
+		`for x := range seq {
+		a = []int{x}
+	}`,
+	} {
+		fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, xs []int, n int, seq func(func(int) bool)) ([]int, []int) {
+	a := prefix
+	` + loop + `
+	b := append(a, 1)
+	c := append(prefix, 2)
+	return b, c
+}`)
+		execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+		t.Logf("%v", execution)
+		// a still aliases prefix if loop body was never executed
+		require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1, loop)
+	}
+}
+
+func TestSwitchNoMatch(t *testing.T) {
+	for _, stmt := range []string{
+		`switch n {
+	case 1:
+		a = []int{1}
+	case 2:
+		a = []int{2}
+	}`,
+		`switch x := v.(type) {
+	case []int:
+		a = []int{1}
+	}`,
+		`select {
+	case <-ch:
+		a = []int{1}
+	}`,
+	} {
+		fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, n int, v any, ch chan int) ([]int, []int) {
+	a := prefix
+	` + stmt + `
+	b := append(a, 1)
+	c := append(prefix, 2)
+	return b, c
+}`)
+		execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+		t.Logf("%v", execution)
+		warnings := ValidateExecution(DefaultFuncSpecCollection, execution)
+		if strings.HasPrefix(stmt, "select") {
+			// select blocks until some clause is chosen, so a is always reassigned
+			require.Empty(t, warnings, stmt)
+		} else {
+			// a still aliases prefix if none of the cases matched
+			require.Len(t, warnings, 1, stmt)
+		}
+	}
+}
+
+func TestSwitchDefault(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, n int) ([]int, []int) {
+	a := prefix
+	switch {
+	case n > 1:
+		a = []int{1}
+	default:
+		a = []int{2}
+	}
+	b := append(a, 1)
+	c := append(prefix, 2)
+	return b, c
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+}
+
+func TestSwitchFallthrough(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, n int) ([]int, []int) {
+	var a, b []int
+	switch n {
+	case 1:
+		a = append(prefix, 1)
+		fallthrough
+	case 2:
+		b = append(prefix, 2)
+	default:
+		a, b = []int{}, []int{}
+	}
+	return a, b
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+}
+
+func TestSwitchCaseEvaluation(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, n int) ([]int, []int) {
+	switch n {
+	case len(append(prefix, 1)):
+	}
+	return append(prefix, 2), nil
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+}
+
+func TestTypeSwitchBinding(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+
+func f(prefix []int, v any) ([]int, []int) {
+	switch x := any(prefix).(type) {
+	case []int:
+		return append(x, 1), append(prefix, 2)
+	case string:
+		return nil, []int{len(x)}
+	}
+	return nil, nil
+}
+
+func g(prefix []int) ([]int, []int) {
+	switch x := interface{}(prefix).(type) {
+	case []int:
+		_ = x
+	}
+	a := prefix
+	return append(a, 1), append(prefix, 2)
+}`)
+	scopes := NewTypedScopes(DefaultFuncs, info)
+	execution := MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "f"))
+	t.Logf("%v", execution)
+	// conversion to the interface hides the slice from the typed analysis
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+	execution = MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "g"))
+	require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int) ([]int, []int) {
+	switch x := prefix.(type) {
+	case []int:
+		return append(x, 1), append(prefix, 2)
+	default:
+		return append(x, 1), nil
+	}
+}`)
+	execution = MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	// x shares components with prefix in every clause
+	require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+}
+
+func TestSelectCommClause(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, ch chan []int, done chan struct{}) ([]int, []int) {
+	a := prefix
+	for {
+		select {
+		case v := <-ch:
+			a = v
+		case ch <- append(prefix, 1):
+			continue
+		case <-done:
+			break
+		}
+		break
+	}
+	return append(a, 1), append(prefix, 2)
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	// received value is fresh, but break / continue paths keep a aliased with prefix
+	require.NotEmpty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+}
+
+func TestFuncLitCapturedVars(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, items [][]int) {
+	a := append(prefix, 1)
+	sort.Slice(items, func(i, j int) bool {
+		b := append(prefix, 2)
+		return len(a) < len(b)
+	})
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+	require.Len(t, execution.FuncLits, 1)
+	// closure is analyzed separately, so only appends within its own body are compared
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution.FuncLits[0]))
+
+	fset, funcDecl = utils.MustGenFunc(`func f(prefix []int) {
+	go func() {
+		a := append(prefix, 1)
+		defer func() { _ = append(a[:0], 1) }()
+		b := append(prefix, 2)
+		_, _ = a, b
+	}()
+}`)
+	execution = MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	require.Len(t, execution.FuncLits, 1)
+	require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution.FuncLits[0]), 1)
+	require.Len(t, execution.FuncLits[0].FuncLits, 1)
+}
+
+func TestDelayedCallArgs(t *testing.T) {
+	for _, stmt := range []string{"defer use(append(prefix, 1))", "go use(append(prefix, 1))", "defer s.Use(append(prefix, 1))"} {
+		fset, funcDecl := utils.MustGenFunc(`func f(s S, prefix []int) []int {
+	` + stmt + `
+	return append(prefix, 2)
+}`)
+		execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+		t.Logf("%v", execution)
+		// arguments are evaluated at the go or defer statement, before the second append
+		require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1, stmt)
+	}
+}
+
+func TestFuncLitInlinedCall(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int) ([]int, []int) {
+	a := func(s []int) []int {
+		if len(s) > 0 {
+			return append(s, 1)
+		}
+		return nil
+	}(prefix)
+	b := append(prefix, 2)
+	return a, b
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	require.Empty(t, execution.FuncLits)
+	require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+
+	fset, funcDecl = utils.MustGenFunc(`func f(prefix []int) ([]int, []int) {
+	a, b := func() (x, y []int) {
+		x = append(prefix, 1)
+		y = []int{2}
+		return
+	}()
+	return a, append(b, 3)
+}`)
+	execution = MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+
+	// variadic parameter is assigned the fresh slice on every call, so the value from the previous iteration isn't appended again
+	fset, funcDecl = utils.MustGenFunc(`func f(xs []int) [][]int {
+	var all [][]int
+	for _, x := range xs {
+		all = append(all, func(rest ...int) []int { return append(rest, 1) }(x))
+	}
+	return all
+}`)
+	execution = MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+
+	fset, funcDecl = utils.MustGenFunc(`func f(prefix []int) ([]int, []int) {
+	return func(rest ...int) ([]int, []int) { return append(rest, 1), append(rest, 2) }(prefix...)
+}`)
+	execution = MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+}
+
+func TestTypedMethodCalls(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+type Builder struct{ items []int }
+func (b *Builder) Append(v int) []int { return append(b.items, v) }
+func Push[T any](s []T, v T) []T { return append(s, v) }
+func f(b *Builder) ([]int, []int) {
+	x := b.Append(1)
+	y := b.Append(2)
+	return x, y
+}
+func g(b *Builder) ([]int, []int) {
+	x := (*Builder).Append(b, 1)
+	y := b.Append(2)
+	return x, y
+}
+func h(prefix []int) ([]int, []int) {
+	x := Push[int](prefix, 1)
+	y := Push(prefix, 2)
+	return x, y
+}`)
+	const (
+		appendMethodId FuncId = 1
+		pushFuncId     FuncId = 2
+	)
+	funcs := map[string]FuncId{"(*main.Builder).Append": appendMethodId, "main.Push": pushFuncId}
+	specs := FuncSpecCollection{
+		// receiver is the first input of the method spec
+		appendMethodId: NewFuncSpec(
+			FuncMultiInput{{{VarId: 0, Selector: Path{"items"}}}, {{VarId: BlankVarId}}},
+			FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: 0}, GenChange: NextGen}}},
+		),
+		pushFuncId: AppendFuncSpec,
+	}
+	for name, line := range map[string]int{"f": 7, "g": 12, "h": 17} {
+		execution := MustExecutionFromFunc(NewTypedScopes(funcs, info), fset, utils.MustExtractFunc(file, name))
+		t.Logf("%v", execution)
+		warnings := ValidateExecution(specs, execution)
+		require.Len(t, warnings, 1, name)
+		require.Equal(t, line, fset.Position(execution.SourceCodeReferences.References[warnings[0].ExecutionPoint]).Line, name)
+	}
+}
+
+func TestUntypedQualifiedFunc(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []byte, bytes []byte) ([]byte, []byte, []byte) {
+	a := slices.Insert(prefix, 0, 1)
+	b := slices.Insert(prefix, 0, 2)
+	c := bytes.Clone(prefix)
+	return a, b, c
+}`)
+	const insertFuncId FuncId = 1
+	funcs := map[string]FuncId{"slices.Insert": insertFuncId, "bytes.Clone": insertFuncId}
+	execution := MustExecutionFromFunc(NewScopes(funcs), fset, funcDecl)
+	t.Logf("%v", execution)
+	// bytes is a local variable here, so bytes.Clone is not a qualified function call
+	require.Len(t, ValidateExecution(FuncSpecCollection{insertFuncId: AppendFuncSpec}, execution), 1)
+}
+
+func TestMethodReceiver(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+type Stack struct{ items []int }
+func (s *Stack) Push(v int) []int {
+	next := append(s.items, v)
+	s.items = append(s.items, v)
+	return next
+}
+func (s Stack) With(v int) ([]int, []int) {
+	return append(s.items, v), append(s.items, v+1)
+}
+func (Stack) Empty(items []int) bool {
+	return len(items) == 0
+}`)
+	for _, scopes := range []Scopes{NewScopes(DefaultFuncs), NewTypedScopes(DefaultFuncs, info)} {
+		execution := MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "Push"))
+		require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+		require.Len(t, execution.Params, 2)
+		require.Equal(t, execution.Params[:1], execution.OutParams)
+
+		execution = MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "With"))
+		require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+		require.Empty(t, execution.OutParams)
+
+		execution = MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "Empty"))
+		require.Len(t, execution.Params, 2)
+		require.Equal(t, VarId(BlankVarId), execution.Params[0])
+	}
+}
+
+func TestIfWithoutElse(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, n int) ([]int, []int) {
+	a := prefix
+	if n > 0 {
+		a = nil
+	} else if n < 0 {
+		a = []int{}
+	}
+	return append(a, 1), append(prefix, 2)
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	t.Logf("%v", execution)
+	// a still aliases prefix when n == 0
+	require.Len(t, ValidateExecution(DefaultFuncSpecCollection, execution), 1)
+}
+
+func TestValidateExecutionTracesConflict(t *testing.T) {
+	fset, funcDecl := utils.MustGenFunc(`func f(prefix []int, n int) ([]int, []int) {
+	a := prefix
+	if n > 0 {
+		a = append(a, 1)
+	}
+	b := append(prefix, 2)
+	return a, b
+}`)
+	execution := MustExecutionFromFunc(NewScopes(DefaultFuncs), fset, funcDecl)
+	warnings, err := ValidateExecutionTraces(nil, DefaultFuncSpecCollection, execution)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	line := func(point ExecutionPoint) int {
+		return fset.Position(execution.SourceCodeReferences.References[point]).Line
+	}
+	require.Equal(t, 7, line(warnings[0].ExecutionPoint))
+	require.Equal(t, 5, line(warnings[0].ConflictPoint))
+	var lines []int
+	for _, transition := range warnings[0].Trace {
+		if _, ok := execution.SourceCodeReferences.References[transition.ToPoint]; ok {
+			lines = append(lines, line(transition.ToPoint))
+		}
+	}
+	require.Contains(t, lines, 5)
+	require.Contains(t, lines, 7)
+}
@@ -7,12 +7,17 @@ import (
 	"github.com/sivukhin/gomakus/utils"
 )
 
+// maxFactorizationPathLength bounds selector paths derived by the factorization: longer paths are truncated, so the tail is merged into the last component
+// Cycles of the assignments which extend the selector (e.g. p = p.next through the temporary variable) derive infinitely long paths otherwise
+const maxFactorizationPathLength = 8
+
 type factorizationEntry struct {
 	varId VarId
 	path  string
 }
 
 type factorizationContext struct {
+	budget    *Budget
 	analyzed  map[factorizationEntry]struct{}
 	rules     FactorizationRules
 	sources   map[VarId][]AssignSelectorOp
@@ -21,6 +26,11 @@ type factorizationContext struct {
 }
 
 func FactorizeAssignments(assigns []AssignSelectorOp) FactorizationRules {
+	return factorizeAssignments(nil, assigns)
+}
+
+// factorizeAssignments stops early if the budget is exceeded: returned rules are incomplete in this case and must not be used
+func factorizeAssignments(budget *Budget, assigns []AssignSelectorOp) FactorizationRules {
 	targets := make(map[VarId][]AssignSelectorOp)
 	sources := make(map[VarId][]AssignSelectorOp)
 	for _, assign := range assigns {
@@ -37,6 +47,7 @@ func FactorizeAssignments(assigns []AssignSelectorOp) FactorizationRules {
 
 	var workspace [1024]byte
 	context := factorizationContext{
+		budget:    budget,
 		analyzed:  make(map[factorizationEntry]struct{}),
 		rules:     make(FactorizationRules),
 		sources:   sources,
@@ -63,9 +74,12 @@ func FactorizeAssignments(assigns []AssignSelectorOp) FactorizationRules {
 }
 
 func factorizeAssigment(context factorizationContext, varId VarId, path Path) {
-	if varId == BlankVarId {
+	if varId == BlankVarId || context.budget.step() {
 		return
 	}
+	if len(path) > maxFactorizationPathLength {
+		path = path[:maxFactorizationPathLength]
+	}
 	pathBytes := joinTo(context.workspace, path, ",")
 	entry := factorizationEntry{varId: varId, path: string(pathBytes)}
 	if _, ok := context.analyzed[entry]; ok {
@@ -22,7 +22,8 @@ func (r FactorizationRules) FactorizeSelector(selector VarSelector) []VarSelecto
 	}
 	factorized := make([]VarSelector, 0)
 	for _, factorizedPath := range paths {
-		if hasPrefix(factorizedPath, selector.Selector) {
+		// selector deeper than the truncated path (see maxFactorizationPathLength) is a part of the component
+		if hasPrefix(factorizedPath, selector.Selector) || hasPrefix(selector.Selector, factorizedPath) {
 			factorized = append(factorized, VarSelector{
 				VarId:    selector.VarId,
 				Selector: factorizedPath,
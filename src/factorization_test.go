@@ -60,7 +60,7 @@ func TestFactorizationHard(t *testing.T) {
 	panic(fmt.Errorf("unexpected expression"))
 }
 `)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -32,9 +32,12 @@ type FuncSingleOutput []FuncOutputRef
 type FuncMultiOutput []FuncSingleOutput
 
 // FuncSpec is a collection of all potential function outcomes (considering non-linear function control)
+// Updates holds new states of the inputs which remain visible to the caller after the call (e.g. pointer receivers) keyed by the input index
+// Unchanged components of the inputs are omitted, so Updates is nil for the functions which don't modify their inputs
 type FuncSpec struct {
 	Inputs  FuncMultiInput
 	Outputs FuncMultiOutput
+	Updates map[int]FuncSingleOutput
 }
 
 type FuncSpecCollection map[FuncId]FuncSpec
@@ -51,27 +54,47 @@ func NewFuncSpec(inputs FuncMultiInput, outputs FuncMultiOutput) FuncSpec {
 		}
 	}
 	for _, output := range outputs {
-		for _, selector := range output {
-			utils.Assertf(
-				selector.InputRef.ArgIndex == BlankVarId ||
-					(0 <= selector.InputRef.ArgIndex && selector.InputRef.ArgIndex < len(inputs) && 0 <= selector.InputRef.SelectorIndex && selector.InputRef.SelectorIndex < len(inputs[selector.InputRef.ArgIndex])),
-				"input ref should point to defined input argument or BlankVarId: %#v", selector.InputRef,
-			)
-		}
+		assertInputRefs(inputs, output)
 	}
 	return FuncSpec{Inputs: inputs, Outputs: outputs}
 }
 
+// WithUpdates returns the spec which also updates the inputs visible to the caller (see FuncSpec.Updates)
+func (s FuncSpec) WithUpdates(updates map[int]FuncSingleOutput) FuncSpec {
+	for argIndex, update := range updates {
+		utils.Assertf(0 <= argIndex && argIndex < len(s.Inputs), "updated input should be defined: %v", argIndex)
+		assertInputRefs(s.Inputs, update)
+	}
+	if len(updates) > 0 {
+		s.Updates = updates
+	}
+	return s
+}
+
+func assertInputRefs(inputs FuncMultiInput, output FuncSingleOutput) {
+	for _, selector := range output {
+		utils.Assertf(
+			selector.InputRef.ArgIndex == BlankVarId ||
+				(0 <= selector.InputRef.ArgIndex && selector.InputRef.ArgIndex < len(inputs) && 0 <= selector.InputRef.SelectorIndex && selector.InputRef.SelectorIndex < len(inputs[selector.InputRef.ArgIndex])),
+			"input ref should point to defined input argument or BlankVarId: %#v", selector.InputRef,
+		)
+	}
+}
+
 var (
 	SliceFuncSpec = NewFuncSpec(
 		FuncMultiInput{{{VarId: 0}}},
 		FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: 0}, GenChange: PrevGen}}},
 	)
 	AppendFuncSpec = NewFuncSpec(
-		FuncMultiInput{{{VarId: 0}}, {{VarId: BlankVarId}}},
+		FuncMultiInput{{{VarId: 0}}, {{VarId: 1}}},
 		FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: 0}, GenChange: NextGen}}},
 	)
 
+	DefaultFuncs = map[string]FuncId{
+		SliceFuncName:  SliceFuncId,
+		AppendFuncName: AppendFuncId,
+	}
 	DefaultFuncSpecCollection = map[FuncId]FuncSpec{
 		SliceFuncId:  SliceFuncSpec,
 		AppendFuncId: AppendFuncSpec,
@@ -0,0 +1,536 @@
+package src
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/sivukhin/gomakus/utils"
+)
+
+// Textual notation of the FuncSpec: [name](inputs) -> outputs
+//
+//	append(s, _) -> next(s)
+//	Split(p) -> (prev(p.a), fresh)
+//	(p{a, b}) -> {x: p.b, y: fresh}
+//	(s{items}, _) -> (); s = {items: next(s.items)}
+//
+// Every input is a parameter name ("_" for the parameter which is not referenced by the outputs) with optional list of its components in braces ("." for the whole parameter)
+// If components are omitted - they are collected from the references in the outputs (or whole parameter is used if there are no references)
+// Outputs are either single result or parenthesized list of results
+// Result is "fresh", reference to the input component with optional generation change (prev, same, next) or braced list of the result components
+// Outputs can be followed by the new states of the inputs visible to the caller after the call (see FuncSpec.Updates): ; name = result, ...
+// Name is optional and can be arbitrary (e.g. (*github.com/acme/pool.Buffer).Grow), it is not a part of the FuncSpec
+
+const (
+	dslFresh      = "fresh"
+	dslBlank      = "_"
+	dslWholeInput = "."
+)
+
+var dslGens = map[string]GenChangeType{"prev": PrevGen, "same": SameGen, "next": NextGen}
+
+// ParseFuncSpec parses FuncSpec in the textual notation and returns it together with the function name (empty if omitted)
+func ParseFuncSpec(text string) (string, FuncSpec, error) {
+	arrow := strings.Index(text, "->")
+	if arrow == -1 {
+		return "", FuncSpec{}, fmt.Errorf("'->' is missing in spec %q", text)
+	}
+	signature := strings.TrimSpace(text[:arrow])
+	if !strings.HasSuffix(signature, ")") {
+		return "", FuncSpec{}, fmt.Errorf("inputs must be parenthesized in spec %q", text)
+	}
+	open := matchingParen(signature)
+	if open == -1 {
+		return "", FuncSpec{}, fmt.Errorf("unbalanced parentheses in spec %q", text)
+	}
+	name := strings.TrimSpace(signature[:open])
+	spec, err := parseFuncSpec(signature[open:], text[arrow+len("->"):])
+	if err != nil {
+		return "", FuncSpec{}, fmt.Errorf("invalid spec %q: %w", text, err)
+	}
+	return name, spec, nil
+}
+
+// MustParseFuncSpec is a ParseFuncSpec for the specs defined in code: it panics if spec is invalid and ignores the name
+func MustParseFuncSpec(text string) FuncSpec {
+	_, spec, err := ParseFuncSpec(text)
+	utils.Assertf(err == nil, "unable to parse func spec: %v", err)
+	return spec
+}
+
+// matchingParen returns position of the parenthesis which matches the last one in the text
+func matchingParen(text string) int {
+	depth := 0
+	for i := len(text) - 1; i >= 0; i-- {
+		switch text[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+type dslInput struct {
+	name       string
+	components []string // nil if components must be collected from the references
+}
+
+type dslUpdate struct {
+	name   string
+	output []dslRef
+}
+
+type dslRef struct {
+	path  string // dot-separated path of the result component (empty for the whole result)
+	fresh bool
+	name  string
+	field string // dot-separated path of the input component (empty for the whole input)
+	gen   GenChangeType
+}
+
+func parseFuncSpec(inputsText, outputsText string) (spec FuncSpec, err error) {
+	inputsParser := &dslParser{tokens: dslTokens(inputsText)}
+	inputs, err := inputsParser.inputs()
+	if err != nil {
+		return FuncSpec{}, err
+	}
+	outputsParser := &dslParser{tokens: dslTokens(outputsText)}
+	outputs, err := outputsParser.outputs()
+	if err != nil {
+		return FuncSpec{}, err
+	}
+	updates, err := outputsParser.updates()
+	if err != nil {
+		return FuncSpec{}, err
+	}
+
+	argIndex := make(map[string]int, len(inputs))
+	for i, input := range inputs {
+		if input.name == dslBlank {
+			continue
+		}
+		if _, ok := argIndex[input.name]; ok {
+			return FuncSpec{}, fmt.Errorf("input %v is declared twice", input.name)
+		}
+		argIndex[input.name] = i
+	}
+	references := slices.Clone(outputs)
+	for _, update := range updates {
+		references = append(references, update.output)
+	}
+	for _, output := range references {
+		for _, ref := range output {
+			if ref.fresh {
+				continue
+			}
+			i, ok := argIndex[ref.name]
+			if !ok {
+				return FuncSpec{}, fmt.Errorf("unknown input %v", ref.name)
+			}
+			if inputsParser.implicit[i] && !slices.Contains(inputs[i].components, ref.field) {
+				inputs[i].components = append(inputs[i].components, ref.field)
+			}
+		}
+	}
+
+	specInputs := make(FuncMultiInput, len(inputs))
+	for i, input := range inputs {
+		if input.name == dslBlank {
+			specInputs[i] = FuncSingleInput{{VarId: VarId(i)}}
+			continue
+		}
+		components := input.components
+		if components == nil && inputsParser.implicit[i] {
+			components = []string{""}
+		}
+		for j, component := range components {
+			if slices.Contains(components[:j], component) {
+				return FuncSpec{}, fmt.Errorf("component %v of input %v is declared twice", component, input.name)
+			}
+			specInputs[i] = append(specInputs[i], VarSelector{VarId: VarId(i), Selector: dslPath(component)})
+		}
+	}
+	specOutput := func(output []dslRef) (FuncSingleOutput, error) {
+		specOutput := FuncSingleOutput{}
+		for _, ref := range output {
+			outputRef := FuncOutputRef{InputRef: FuncInputRef{ArgIndex: BlankVarId}, OutputPath: dslPath(ref.path)}
+			if !ref.fresh {
+				arg := argIndex[ref.name]
+				selectorIndex := slices.Index(inputs[arg].components, ref.field)
+				if selectorIndex == -1 {
+					return nil, fmt.Errorf("component %v of input %v is not declared", ref.field, ref.name)
+				}
+				outputRef.InputRef = FuncInputRef{ArgIndex: arg, SelectorIndex: selectorIndex}
+				outputRef.GenChange = ref.gen
+			}
+			specOutput = append(specOutput, outputRef)
+		}
+		return specOutput, nil
+	}
+	specOutputs := make(FuncMultiOutput, len(outputs))
+	for i, output := range outputs {
+		if specOutputs[i], err = specOutput(output); err != nil {
+			return FuncSpec{}, err
+		}
+	}
+	specUpdates := make(map[int]FuncSingleOutput, len(updates))
+	for _, update := range updates {
+		i, ok := argIndex[update.name]
+		if !ok {
+			return FuncSpec{}, fmt.Errorf("unknown updated input %v", update.name)
+		}
+		if _, ok := specUpdates[i]; ok {
+			return FuncSpec{}, fmt.Errorf("input %v is updated twice", update.name)
+		}
+		if specUpdates[i], err = specOutput(update.output); err != nil {
+			return FuncSpec{}, err
+		}
+	}
+	// NewFuncSpec asserts consistency of the spec, so its panic is converted to the error here
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return NewFuncSpec(specInputs, specOutputs).WithUpdates(specUpdates), nil
+}
+
+func dslTokens(text string) []string {
+	var tokens []string
+	for i := 0; i < len(text); {
+		r := rune(text[i])
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			j := i
+			for j < len(text) && (text[j] == '_' || unicode.IsLetter(rune(text[j])) || unicode.IsDigit(rune(text[j]))) {
+				j++
+			}
+			tokens = append(tokens, text[i:j])
+			i = j
+		default:
+			tokens = append(tokens, text[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+type dslParser struct {
+	tokens   []string
+	position int
+	// implicit marks inputs which components are collected from the references
+	implicit map[int]bool
+}
+
+func (p *dslParser) peek() string {
+	if p.position < len(p.tokens) {
+		return p.tokens[p.position]
+	}
+	return ""
+}
+
+func (p *dslParser) next() string {
+	token := p.peek()
+	p.position++
+	return token
+}
+
+func (p *dslParser) expect(token string) error {
+	if actual := p.next(); actual != token {
+		return fmt.Errorf("expected %q, got %q", token, actual)
+	}
+	return nil
+}
+
+func (p *dslParser) end() error {
+	if p.position < len(p.tokens) {
+		return fmt.Errorf("unexpected %q", p.peek())
+	}
+	return nil
+}
+
+func (p *dslParser) ident() (string, error) {
+	token := p.next()
+	if token == "" || !(token[0] == '_' || unicode.IsLetter(rune(token[0]))) {
+		return "", fmt.Errorf("expected identifier, got %q", token)
+	}
+	return token, nil
+}
+
+// path parses dot-separated path of identifiers
+func (p *dslParser) path() (string, error) {
+	first, err := p.ident()
+	if err != nil {
+		return "", err
+	}
+	path := []string{first}
+	for p.peek() == "." {
+		p.next()
+		name, err := p.ident()
+		if err != nil {
+			return "", err
+		}
+		path = append(path, name)
+	}
+	return strings.Join(path, "."), nil
+}
+
+// list parses comma-separated list of elements until the closing token
+func (p *dslParser) list(closing string, element func() error) error {
+	if p.peek() == closing {
+		p.next()
+		return nil
+	}
+	for {
+		if err := element(); err != nil {
+			return err
+		}
+		if token := p.next(); token == closing {
+			return nil
+		} else if token != "," {
+			return fmt.Errorf("expected %q or %q, got %q", ",", closing, token)
+		}
+	}
+}
+
+func (p *dslParser) inputs() ([]dslInput, error) {
+	p.implicit = make(map[int]bool)
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var inputs []dslInput
+	err := p.list(")", func() error {
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		input := dslInput{name: name}
+		if name != dslBlank && p.peek() == "{" {
+			p.next()
+			input.components = []string{}
+			err = p.list("}", func() error {
+				if p.peek() == dslWholeInput {
+					p.next()
+					input.components = append(input.components, "")
+					return nil
+				}
+				component, err := p.path()
+				input.components = append(input.components, component)
+				return err
+			})
+		} else if name != dslBlank {
+			p.implicit[len(inputs)] = true
+		}
+		inputs = append(inputs, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inputs, p.end()
+}
+
+func (p *dslParser) outputs() ([][]dslRef, error) {
+	var outputs [][]dslRef
+	if p.peek() == "(" {
+		p.next()
+		err := p.list(")", func() error {
+			output, err := p.output()
+			outputs = append(outputs, output)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		output, err := p.output()
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs, nil
+}
+
+// updates parses optional list of the updated inputs after the outputs: ; name = result, ...
+func (p *dslParser) updates() ([]dslUpdate, error) {
+	var updates []dslUpdate
+	if p.peek() != ";" {
+		return nil, p.end()
+	}
+	p.next()
+	for {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		output, err := p.output()
+		if err != nil {
+			return nil, err
+		}
+		updates = append(updates, dslUpdate{name: name, output: output})
+		if p.peek() != "," {
+			return updates, p.end()
+		}
+		p.next()
+	}
+}
+
+func (p *dslParser) output() ([]dslRef, error) {
+	if p.peek() != "{" {
+		ref, err := p.ref()
+		return []dslRef{ref}, err
+	}
+	p.next()
+	refs := []dslRef{}
+	err := p.list("}", func() error {
+		path, err := p.path()
+		if err != nil {
+			return err
+		}
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		ref, err := p.ref()
+		ref.path = path
+		refs = append(refs, ref)
+		return err
+	})
+	return refs, err
+}
+
+func (p *dslParser) ref() (dslRef, error) {
+	if p.peek() == dslFresh {
+		p.next()
+		return dslRef{fresh: true}, nil
+	}
+	// generator names are valid input names too, so they select the generator only when followed by "("
+	var gen GenChangeType
+	ok := p.position+1 < len(p.tokens) && p.tokens[p.position+1] == "("
+	if ok {
+		if gen, ok = dslGens[p.peek()]; ok {
+			p.next()
+			p.next()
+		}
+	}
+	path, err := p.path()
+	if err != nil {
+		return dslRef{}, err
+	}
+	if ok {
+		if err := p.expect(")"); err != nil {
+			return dslRef{}, err
+		}
+	}
+	name, field, _ := strings.Cut(path, ".")
+	return dslRef{name: name, field: field, gen: gen}, nil
+}
+
+// String formats spec in the textual notation accepted by ParseFuncSpec; inputs are named p0, p1, ...
+func (s FuncSpec) String() string {
+	var b strings.Builder
+	inputName := func(i int) string { return fmt.Sprintf("p%v", i) }
+	referenced := make(map[int]bool)
+	for _, output := range s.Outputs {
+		for _, ref := range output {
+			referenced[ref.InputRef.ArgIndex] = true
+		}
+	}
+	for argIndex, update := range s.Updates {
+		referenced[argIndex] = true
+		for _, ref := range update {
+			referenced[ref.InputRef.ArgIndex] = true
+		}
+	}
+	b.WriteString("(")
+	for i, input := range s.Inputs {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		if len(input) == 1 && (input[0].VarId == BlankVarId || len(input[0].Selector) == 0 && !referenced[i]) {
+			b.WriteString(dslBlank)
+			continue
+		}
+		b.WriteString(inputName(i))
+		if len(input) == 1 && len(input[0].Selector) == 0 {
+			continue
+		}
+		components := make([]string, len(input))
+		for j, selector := range input {
+			components[j] = strings.Join(selector.Selector, ".")
+			if components[j] == "" {
+				components[j] = dslWholeInput
+			}
+		}
+		b.WriteString("{" + strings.Join(components, ", ") + "}")
+	}
+	b.WriteString(") -> ")
+
+	formatRef := func(ref FuncOutputRef) string {
+		if ref.InputRef.ArgIndex == BlankVarId {
+			return dslFresh
+		}
+		name := inputName(ref.InputRef.ArgIndex)
+		if selector := s.Inputs[ref.InputRef.ArgIndex][ref.InputRef.SelectorIndex].Selector; len(selector) > 0 {
+			name += "." + strings.Join(selector, ".")
+		}
+		switch ref.GenChange {
+		case PrevGen:
+			return "prev(" + name + ")"
+		case NextGen:
+			return "next(" + name + ")"
+		}
+		return name
+	}
+	formatOutput := func(output FuncSingleOutput) string {
+		if len(output) == 1 && len(output[0].OutputPath) == 0 {
+			return formatRef(output[0])
+		}
+		components := make([]string, len(output))
+		for j, ref := range output {
+			components[j] = strings.Join(ref.OutputPath, ".") + ": " + formatRef(ref)
+		}
+		return "{" + strings.Join(components, ", ") + "}"
+	}
+	outputs := make([]string, len(s.Outputs))
+	for i, output := range s.Outputs {
+		outputs[i] = formatOutput(output)
+	}
+	if len(outputs) == 1 {
+		b.WriteString(outputs[0])
+	} else {
+		b.WriteString("(" + strings.Join(outputs, ", ") + ")")
+	}
+	if len(s.Updates) > 0 {
+		argIndexes := make([]int, 0, len(s.Updates))
+		for argIndex := range s.Updates {
+			argIndexes = append(argIndexes, argIndex)
+		}
+		slices.Sort(argIndexes)
+		updates := make([]string, len(argIndexes))
+		for i, argIndex := range argIndexes {
+			updates[i] = inputName(argIndex) + " = " + formatOutput(s.Updates[argIndex])
+		}
+		b.WriteString("; " + strings.Join(updates, ", "))
+	}
+	return b.String()
+}
+
+func dslPath(path string) Path {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
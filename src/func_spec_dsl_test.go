@@ -0,0 +1,75 @@
+package src
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFuncSpec(t *testing.T) {
+	name, spec, err := ParseFuncSpec("append(s, _) -> next(s)")
+	require.NoError(t, err)
+	require.Equal(t, "append", name)
+	require.Equal(t, AppendFuncSpec, spec)
+
+	name, spec, err = ParseFuncSpec("(*github.com/acme/pool.Pair).Split(p) -> (prev(p.a), fresh)")
+	require.NoError(t, err)
+	require.Equal(t, "(*github.com/acme/pool.Pair).Split", name)
+	require.Equal(t, FuncSpec{
+		Inputs: FuncMultiInput{{{VarId: 0, Selector: Path{"a"}}}},
+		Outputs: FuncMultiOutput{
+			{{InputRef: FuncInputRef{ArgIndex: 0}, GenChange: PrevGen}},
+			{{InputRef: FuncInputRef{ArgIndex: BlankVarId}}},
+		},
+	}, spec)
+
+	require.Equal(t, FuncSpec{
+		Inputs: FuncMultiInput{{{VarId: 0, Selector: Path{"a"}}, {VarId: 0, Selector: Path{"b"}}}, {{VarId: 1}}},
+		Outputs: FuncMultiOutput{{
+			{InputRef: FuncInputRef{ArgIndex: 0, SelectorIndex: 1}, OutputPath: Path{"x"}, GenChange: NextGen},
+			{InputRef: FuncInputRef{ArgIndex: BlankVarId}, OutputPath: Path{"y", "z"}},
+		}},
+	}, MustParseFuncSpec("(p{a, b}, v) -> {x: next(p.b), y.z: fresh}"))
+
+	require.Equal(t, FuncSpec{
+		Inputs:  FuncMultiInput{{{VarId: 0}}, {{VarId: 1}}},
+		Outputs: FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: 0}}}, {{InputRef: FuncInputRef{ArgIndex: 0}, GenChange: NextGen}}},
+	}, MustParseFuncSpec("(next, _) -> (next, next(next))"))
+}
+
+func TestParseFuncSpecErrors(t *testing.T) {
+	for _, text := range []string{
+		"(s)",
+		"s -> s",
+		"(s, s) -> s",
+		"(s) -> t",
+		"(p{a}) -> p.b",
+		"(p{a, a}) -> p.a",
+		"(s) -> next(s",
+		"(s) -> (s, s",
+		"(s) -> s s",
+		"(s) -> (); t = s",
+		"(s, t) -> (); s = t, s = fresh",
+		"(s) -> (); s",
+	} {
+		_, _, err := ParseFuncSpec(text)
+		require.Error(t, err, text)
+	}
+}
+
+func TestFuncSpecString(t *testing.T) {
+	require.Equal(t, "(p0) -> prev(p0)", SliceFuncSpec.String())
+	require.Equal(t, "(p0, _) -> next(p0)", AppendFuncSpec.String())
+	for _, text := range []string{
+		"(p0{a, b}) -> (p0.b, prev(p0.a))",
+		"(p0{., a.b}, _) -> ({x: p0, y: next(p0.a.b)}, fresh, {})",
+		"(p0{}) -> ()",
+		"(_, _) -> fresh",
+		"(_, p1, _) -> next(p1)",
+		"(p0, _, _) -> (p0, fresh)",
+		"(p0{items}, _) -> (); p0 = {items: next(p0.items)}",
+		"(p0, p1) -> fresh; p0 = p1, p1 = fresh",
+	} {
+		require.Equal(t, text, MustParseFuncSpec(text).String())
+	}
+}
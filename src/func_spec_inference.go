@@ -0,0 +1,244 @@
+package src
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// FuncSpecInferenceLimit bounds the number of passes over the call graph in InferFuncSpecs
+// Specs of recursive functions usually stabilize after few passes, so the limit only protects from oscillation
+const FuncSpecInferenceLimit = 16
+
+// InferFuncSpecs derives specs of the executions (keyed by the FuncId under which function is registered in Scopes.Funcs) on top of the known specs
+// Specs are recomputed until they stop changing, so aliasing is propagated through the chains of helpers and recursive calls
+// Errors of the functions which inference failed are returned keyed by their FuncId (see InferFuncSpec)
+func InferFuncSpecs(funcs FuncSpecCollection, executions map[FuncId]Execution) (FuncSpecCollection, map[FuncId]error) {
+	return InferFuncSpecsWithin(context.Background(), Limits{}, funcs, executions)
+}
+
+// InferFuncSpecsWithin is InferFuncSpecs which bounds every inference of the function spec with the limits
+// Functions which inference exceeds the budget or fails are left without spec (so their results are treated as fresh values)
+func InferFuncSpecsWithin(ctx context.Context, limits Limits, funcs FuncSpecCollection, executions map[FuncId]Execution) (FuncSpecCollection, map[FuncId]error) {
+	specs := maps.Clone(funcs)
+	errs := make(map[FuncId]error)
+	for i := 0; i < FuncSpecInferenceLimit; i++ {
+		changed := false
+		for funcId, execution := range executions {
+			spec, ok, err := InferFuncSpec(NewBudget(ctx, limits), specs, execution)
+			if err != nil {
+				errs[funcId] = err
+			} else {
+				delete(errs, funcId)
+			}
+			if !ok {
+				continue
+			}
+			if current, exists := specs[funcId]; !exists || !reflect.DeepEqual(current, spec) {
+				specs[funcId] = spec
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return specs, errs
+}
+
+// InferFuncSpec derives spec of the function from its execution: every component of the returned values is traced back to the parameter component it came from
+// If different paths return different components for the same output - input component with the biggest generation wins (fresh value is used only if no input is returned)
+// Components of the OutParams are traced back in the same way at the exit point of the function and are returned as FuncSpec.Updates
+// False is returned for the functions without results and out params
+// If the budget is exceeded, error wrapping ErrBudgetExceeded is returned; internal failure of the inference is returned as the error wrapping ErrInternal
+func InferFuncSpec(budget *Budget, funcs FuncSpecCollection, execution Execution) (spec FuncSpec, ok bool, err error) {
+	defer recoverInternal(&err)
+	if len(execution.Results) == 0 && len(execution.OutParams) == 0 {
+		return FuncSpec{}, false, nil
+	}
+	simplified, simplification := newSimplification(SimplificationContext{Funcs: funcs, Budget: budget}, execution)
+	if err := budget.Err(); err != nil {
+		return FuncSpec{}, false, err
+	}
+
+	inputs := make(FuncMultiInput, len(execution.Params))
+	inputOrigins := make(map[VarId]FuncInputRef)
+	inputSelectors := make(map[string]FuncInputRef)
+	for i, param := range execution.Params {
+		if param == BlankVarId {
+			inputs[i] = FuncSingleInput{{VarId: VarId(i)}}
+			continue
+		}
+		for j, selector := range simplification.factorization.FactorizeSelector(VarSelector{VarId: param}) {
+			inputs[i] = append(inputs[i], VarSelector{VarId: VarId(i), Selector: specPath(selector.Selector)})
+			inputSelectors[selector.String()] = FuncInputRef{ArgIndex: i, SelectorIndex: j}
+			if varId, ok := simplification.varSelectorCollection[selector.String()]; ok {
+				inputOrigins[VarId(varId)] = FuncInputRef{ArgIndex: i, SelectorIndex: j}
+			}
+		}
+	}
+
+	returnPoints := make(map[ExecutionPoint][]VarId)
+	for _, transitions := range execution.Transitions {
+		for _, transition := range transitions {
+			if op, ok := transition.Operation.(ReturnVarsOp); ok {
+				returnPoints[transition.ToPoint] = op.VarIds
+				if op.VarIds == nil { // naked return
+					returnPoints[transition.ToPoint] = execution.Results
+				}
+			}
+		}
+	}
+
+	outputs := make(FuncMultiOutput, len(execution.Results))
+	outputRefs := make([]map[string]FuncOutputRef, len(execution.Results))
+	for i := range outputRefs {
+		outputRefs[i] = make(map[string]FuncOutputRef)
+	}
+	updateRefs := make([]map[string]FuncOutputRef, len(execution.OutParams))
+	for i := range updateRefs {
+		updateRefs[i] = make(map[string]FuncOutputRef)
+	}
+	// input components are distinguished from the fresh values by the negative origin ids
+	initialGen := make(map[VarId]VarGen, len(inputOrigins))
+	origins := make(map[int]FuncInputRef, len(inputOrigins))
+	for varId, inputRef := range inputOrigins {
+		id := -len(origins) - 1
+		origins[id] = inputRef
+		initialGen[varId] = VarGen{Id: id}
+	}
+	// fresh values are not distinguished from each other, so all of them are represented with the zero VarGen
+	fresh := VarGen{}
+	genOf := func(state varGens[VarGen], varId VarId) map[VarGen]struct{} {
+		if gens, ok := state[varId]; ok {
+			return gens
+		}
+		return map[VarGen]struct{}{fresh: {}}
+	}
+	root := make(varGens[VarGen])
+	for _, varId := range assignedVars(simplified) {
+		root[varId] = map[VarGen]struct{}{fresh: {}}
+	}
+	for varId, gen := range initialGen {
+		root[varId] = map[VarGen]struct{}{gen: {}}
+	}
+	// collect merges components of the variable value into the refs of the output components
+	collect := func(refs map[string]FuncOutputRef, value VarId, state varGens[VarGen]) {
+		for _, selector := range simplification.factorization.FactorizeSelector(VarSelector{VarId: value}) {
+			key := strings.Join(selector.Selector, ".")
+			gens := map[VarGen]struct{}{fresh: {}}
+			if varId, ok := simplification.varSelectorCollection[selector.String()]; ok {
+				gens = genOf(state, VarId(varId))
+			} else if inputRef, ok := inputSelectors[selector.String()]; ok {
+				// component of the parameter which is never used keeps its initial value
+				refs[key] = FuncOutputRef{InputRef: inputRef, OutputPath: specPath(selector.Selector)}
+				continue
+			}
+			for gen := range gens {
+				ref := FuncOutputRef{InputRef: FuncInputRef{ArgIndex: BlankVarId}, OutputPath: specPath(selector.Selector)}
+				if inputRef, isInput := origins[gen.Id]; isInput {
+					ref.InputRef, ref.GenChange = inputRef, genChange(gen.Gen)
+				}
+				if current, exists := refs[key]; !exists || dominates(ref, current) {
+					refs[key] = ref
+				}
+			}
+		}
+	}
+	propagateDataflow(budget, simplified, root, func(state varGens[VarGen], transition ExecutionTransition) varGens[VarGen] {
+		next := state
+		if op, ok := transition.Operation.(AssignVarOp); ok && op.ToVarId != BlankVarId {
+			next = state.clone()
+			next[op.ToVarId] = map[VarGen]struct{}{fresh: {}}
+			if op.FromVarId != BlankVarId {
+				next[op.ToVarId] = make(map[VarGen]struct{})
+				for source := range genOf(state, op.FromVarId) {
+					target := fresh
+					if source.Id < 0 {
+						target = VarGen{Id: source.Id, Gen: widenLag(source.Gen + int(op.GenChange))}
+					}
+					next[op.ToVarId][target] = struct{}{}
+				}
+			}
+		}
+		original := simplification.simplifiedToOriginal[transition.ToPoint]
+		if original == execution.ExitPoint {
+			for i, param := range execution.OutParams {
+				collect(updateRefs[i], param, next)
+			}
+		}
+		results, ok := returnPoints[original]
+		if !ok || len(results) != len(execution.Results) {
+			return next
+		}
+		for i, result := range results {
+			if result != BlankVarId {
+				collect(outputRefs[i], result, next)
+			}
+		}
+		return next
+	})
+	if err := budget.Err(); err != nil {
+		return FuncSpec{}, false, err
+	}
+	for i, refs := range outputRefs {
+		outputs[i] = sortedOutput(refs)
+	}
+	updates := make(map[int]FuncSingleOutput)
+	for i, refs := range updateRefs {
+		argIndex := slices.Index(execution.Params, execution.OutParams[i])
+		// components which keep the initial value of the input are not updated
+		for key, ref := range refs {
+			if ref.InputRef.ArgIndex == argIndex && ref.GenChange == SameGen && slices.Equal(inputs[argIndex][ref.InputRef.SelectorIndex].Selector, ref.OutputPath) {
+				delete(refs, key)
+			}
+		}
+		if len(refs) > 0 {
+			updates[argIndex] = sortedOutput(refs)
+		}
+	}
+	return NewFuncSpec(inputs, outputs).WithUpdates(updates), true, nil
+}
+
+// sortedOutput collects refs of the output components ordered by their paths
+func sortedOutput(refs map[string]FuncOutputRef) FuncSingleOutput {
+	output := FuncSingleOutput{}
+	for _, ref := range refs {
+		output = append(output, ref)
+	}
+	sort.Slice(output, func(a, b int) bool { return slices.Compare(output[a].OutputPath, output[b].OutputPath) < 0 })
+	return output
+}
+
+// dominates returns true if ref must replace current ref of the same output component:
+// references to the inputs are preferred over fresh values and more advanced generations are preferred over older ones
+func dominates(ref, current FuncOutputRef) bool {
+	if ref.InputRef.ArgIndex == BlankVarId {
+		return false
+	}
+	if current.InputRef.ArgIndex == BlankVarId {
+		return true
+	}
+	return ref.GenChange > current.GenChange
+}
+
+// specPath normalizes empty path to nil in order to keep specs comparable
+func specPath(path Path) Path {
+	if len(path) == 0 {
+		return nil
+	}
+	return path
+}
+
+func genChange(gen int) GenChangeType {
+	switch {
+	case gen > 0:
+		return NextGen
+	case gen < 0:
+		return PrevGen
+	}
+	return SameGen
+}
@@ -0,0 +1,142 @@
+package src
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gomakus/utils"
+)
+
+func TestInferFuncSpec(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+type Pair struct{ a, b []int }
+func with(prefix []int, v int) []int { return append(prefix, v) }
+func same(prefix []int, _ int) []int { return prefix }
+func fresh(prefix []int) []int { return append([]int{}, prefix...) }
+func split(p Pair) (x []int, y []int) {
+	x, y = p.b, p.a[:0:0]
+	return
+}
+func maybe(prefix []int, ok bool) []int {
+	if ok {
+		return append(prefix, 1)
+	}
+	return nil
+}`)
+	scopes := NewTypedScopes(DefaultFuncs, info)
+	infer := func(name string) FuncSpec {
+		spec, ok, err := InferFuncSpec(nil, DefaultFuncSpecCollection, MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, name)))
+		require.NoError(t, err)
+		require.True(t, ok)
+		return spec
+	}
+	require.Equal(t, FuncSpec{
+		Inputs:  FuncMultiInput{{{VarId: 0}}, {{VarId: 1}}},
+		Outputs: FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: 0}, GenChange: NextGen}}},
+	}, infer("with"))
+	require.Equal(t, FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: 0}, GenChange: SameGen}}}, infer("same").Outputs)
+	require.Equal(t, FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: BlankVarId}}}}, infer("fresh").Outputs)
+	require.Equal(t, FuncSpec{
+		Inputs: FuncMultiInput{{{VarId: 0, Selector: Path{"a"}}, {VarId: 0, Selector: Path{"b"}}}},
+		Outputs: FuncMultiOutput{
+			{{InputRef: FuncInputRef{ArgIndex: 0, SelectorIndex: 1}, GenChange: SameGen}},
+			{{InputRef: FuncInputRef{ArgIndex: 0, SelectorIndex: 0}, GenChange: PrevGen}},
+		},
+	}, infer("split"))
+	require.Equal(t, FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: 0}, GenChange: NextGen}}}, infer("maybe").Outputs)
+}
+
+func TestInferFuncSpecInternalError(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+func with(prefix []int, v int) []int { return append(prefix, v) }`)
+	execution := MustExecutionFromFunc(NewTypedScopes(DefaultFuncs, info), fset, utils.MustExtractFunc(file, "with"))
+	// spec refers to the component of the input which is not declared
+	broken := FuncSpec{
+		Inputs:  AppendFuncSpec.Inputs,
+		Outputs: FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: 0, SelectorIndex: 1}, GenChange: NextGen}}},
+	}
+	_, ok, err := InferFuncSpec(nil, FuncSpecCollection{AppendFuncId: broken}, execution)
+	require.ErrorIs(t, err, ErrInternal)
+	require.False(t, ok)
+
+	specs, errs := InferFuncSpecs(FuncSpecCollection{AppendFuncId: broken}, map[FuncId]Execution{1: execution})
+	require.ErrorIs(t, errs[1], ErrInternal)
+	_, ok = specs[1]
+	require.False(t, ok)
+}
+
+func TestInferFuncSpecsFixpoint(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+func with(prefix []int, v int) []int { return withAll(prefix, v) }
+func withAll(prefix []int, vs ...int) []int {
+	if len(vs) == 0 {
+		return prefix
+	}
+	return withAll(append(prefix, vs[0]), vs[1:]...)
+}
+func f(prefix []int) ([]int, []int) {
+	a := with(prefix, 1)
+	b := with(prefix, 2)
+	return a, b
+}`)
+	funcs := map[string]FuncId{SliceFuncName: SliceFuncId, AppendFuncName: AppendFuncId, "main.with": 1, "main.withAll": 2}
+	scopes := NewTypedScopes(funcs, info)
+	executions := map[FuncId]Execution{
+		1: MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "with")),
+		2: MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "withAll")),
+	}
+	execution := MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "f"))
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+
+	specs, errs := InferFuncSpecs(DefaultFuncSpecCollection, executions)
+	require.Empty(t, errs)
+	require.Equal(t, FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: 0}, GenChange: NextGen}}}, specs[1].Outputs)
+	require.Equal(t, FuncMultiOutput{{{InputRef: FuncInputRef{ArgIndex: 0}, GenChange: NextGen}}}, specs[2].Outputs)
+	warnings := ValidateExecution(specs, execution)
+	require.Len(t, warnings, 1)
+	require.Equal(t, 11, fset.Position(execution.SourceCodeReferences.References[warnings[0].ExecutionPoint]).Line)
+}
+
+func TestInferFuncSpecUpdates(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+type Stack struct{ items []int }
+func (s *Stack) Add(v int) { s.items = append(s.items, v) }
+func (s *Stack) Len() int { return len(s.items) }
+func (s Stack) Drop(v int) { s.items = append(s.items, v) }
+func f(a Stack) (Stack, Stack) {
+	b := a
+	a.Add(1)
+	b.Add(2)
+	return a, b
+}
+func g(a Stack) (Stack, Stack) {
+	b := a
+	a.Drop(1)
+	b.Drop(2)
+	_ = a.Len()
+	return a, b
+}`)
+	funcs := map[string]FuncId{SliceFuncName: SliceFuncId, AppendFuncName: AppendFuncId, "(*main.Stack).Add": 1, "(*main.Stack).Len": 2, "main.Stack.Drop": 3}
+	scopes := NewTypedScopes(funcs, info)
+	specs, errs := InferFuncSpecs(DefaultFuncSpecCollection, map[FuncId]Execution{
+		1: MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "Add")),
+		2: MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "Len")),
+		3: MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "Drop")),
+	})
+	require.Empty(t, errs)
+	require.Equal(t, "(p0{items}, _) -> (); p0 = {items: next(p0.items)}", specs[1].String())
+	require.Nil(t, specs[2].Updates)
+	_, ok := specs[3]
+	require.False(t, ok)
+
+	// a and b share the backing array, so the second append through the pointer receiver can overwrite the first one
+	execution := MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "f"))
+	require.Empty(t, ValidateExecution(DefaultFuncSpecCollection, execution))
+	warnings := ValidateExecution(specs, execution)
+	require.Len(t, warnings, 1)
+	require.Equal(t, 9, fset.Position(execution.SourceCodeReferences.References[warnings[0].ExecutionPoint]).Line)
+
+	execution = MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "g"))
+	require.Empty(t, ValidateExecution(specs, execution))
+}
@@ -0,0 +1,79 @@
+package src
+
+// StdFuncSpecs describes standard library functions (keyed by FuncName) which results are either fresh or alias their arguments
+// Functions which write into the spare capacity of the argument (like append) have next generation of it in the result,
+// functions which return subslice of the argument keep its generation (same as s[i:j] does) and allocating functions return fresh values
+// Note that subslices of the argument returned within a new slice (e.g. bytes.Split) can't be expressed with FuncSpec, so the results are fresh
+var StdFuncSpecs = map[string]FuncSpec{
+	// builtins
+	"copy": MustParseFuncSpec("(_, _) -> fresh"),
+	"make": MustParseFuncSpec("(_, _, _) -> fresh"),
+
+	// slices
+	"slices.Clone":       MustParseFuncSpec("(s) -> fresh"),
+	"slices.Clip":        MustParseFuncSpec("(s) -> fresh"), // capacity of the result is equal to its length, so append always reallocates it
+	"slices.Concat":      MustParseFuncSpec("(_) -> fresh"),
+	"slices.Repeat":      MustParseFuncSpec("(_, _) -> fresh"),
+	"slices.Grow":        MustParseFuncSpec("(s, _) -> s"),
+	"slices.Insert":      MustParseFuncSpec("(s, _, _) -> next(s)"),
+	"slices.Replace":     MustParseFuncSpec("(s, _, _, _) -> next(s)"),
+	"slices.Delete":      MustParseFuncSpec("(s, _, _) -> s"),
+	"slices.DeleteFunc":  MustParseFuncSpec("(s, _) -> s"),
+	"slices.Compact":     MustParseFuncSpec("(s) -> s"),
+	"slices.CompactFunc": MustParseFuncSpec("(s, _) -> s"),
+	"slices.AppendSeq":   MustParseFuncSpec("(s, _) -> next(s)"),
+	"slices.Collect":     MustParseFuncSpec("(_) -> fresh"),
+	"slices.Sorted":      MustParseFuncSpec("(_) -> fresh"),
+
+	// bytes
+	"bytes.Clone":         MustParseFuncSpec("(b) -> fresh"),
+	"bytes.Join":          MustParseFuncSpec("(_, _) -> fresh"),
+	"bytes.Repeat":        MustParseFuncSpec("(_, _) -> fresh"),
+	"bytes.Replace":       MustParseFuncSpec("(_, _, _, _) -> fresh"),
+	"bytes.ReplaceAll":    MustParseFuncSpec("(_, _, _) -> fresh"),
+	"bytes.ToLower":       MustParseFuncSpec("(_) -> fresh"),
+	"bytes.ToUpper":       MustParseFuncSpec("(_) -> fresh"),
+	"bytes.Title":         MustParseFuncSpec("(_) -> fresh"),
+	"bytes.Runes":         MustParseFuncSpec("(_) -> fresh"),
+	"bytes.Split":         MustParseFuncSpec("(_, _) -> fresh"),
+	"bytes.SplitN":        MustParseFuncSpec("(_, _, _) -> fresh"),
+	"bytes.SplitAfter":    MustParseFuncSpec("(_, _) -> fresh"),
+	"bytes.SplitAfterN":   MustParseFuncSpec("(_, _, _) -> fresh"),
+	"bytes.Fields":        MustParseFuncSpec("(_) -> fresh"),
+	"bytes.FieldsFunc":    MustParseFuncSpec("(_, _) -> fresh"),
+	"bytes.TrimSpace":     MustParseFuncSpec("(b) -> b"),
+	"bytes.Trim":          MustParseFuncSpec("(b, _) -> b"),
+	"bytes.TrimLeft":      MustParseFuncSpec("(b, _) -> b"),
+	"bytes.TrimRight":     MustParseFuncSpec("(b, _) -> b"),
+	"bytes.TrimPrefix":    MustParseFuncSpec("(b, _) -> b"),
+	"bytes.TrimSuffix":    MustParseFuncSpec("(b, _) -> b"),
+	"bytes.TrimFunc":      MustParseFuncSpec("(b, _) -> b"),
+	"bytes.TrimLeftFunc":  MustParseFuncSpec("(b, _) -> b"),
+	"bytes.TrimRightFunc": MustParseFuncSpec("(b, _) -> b"),
+
+	// strings
+	"strings.Fields":      MustParseFuncSpec("(_) -> fresh"),
+	"strings.FieldsFunc":  MustParseFuncSpec("(_, _) -> fresh"),
+	"strings.Split":       MustParseFuncSpec("(_, _) -> fresh"),
+	"strings.SplitN":      MustParseFuncSpec("(_, _, _) -> fresh"),
+	"strings.SplitAfter":  MustParseFuncSpec("(_, _) -> fresh"),
+	"strings.SplitAfterN": MustParseFuncSpec("(_, _, _) -> fresh"),
+
+	// append-like functions
+	"strconv.AppendBool":             MustParseFuncSpec("(dst, _) -> next(dst)"),
+	"strconv.AppendInt":              MustParseFuncSpec("(dst, _, _) -> next(dst)"),
+	"strconv.AppendUint":             MustParseFuncSpec("(dst, _, _) -> next(dst)"),
+	"strconv.AppendFloat":            MustParseFuncSpec("(dst, _, _, _, _) -> next(dst)"),
+	"strconv.AppendQuote":            MustParseFuncSpec("(dst, _) -> next(dst)"),
+	"strconv.AppendQuoteRune":        MustParseFuncSpec("(dst, _) -> next(dst)"),
+	"strconv.AppendQuoteToASCII":     MustParseFuncSpec("(dst, _) -> next(dst)"),
+	"strconv.AppendQuoteRuneToASCII": MustParseFuncSpec("(dst, _) -> next(dst)"),
+	"strconv.AppendQuoteToGraphic":   MustParseFuncSpec("(dst, _) -> next(dst)"),
+	"fmt.Append":                     MustParseFuncSpec("(b, _) -> next(b)"),
+	"fmt.Appendf":                    MustParseFuncSpec("(b, _, _) -> next(b)"),
+	"fmt.Appendln":                   MustParseFuncSpec("(b, _) -> next(b)"),
+	"unicode/utf8.AppendRune":        MustParseFuncSpec("(p, _) -> next(p)"),
+	"encoding/binary.AppendUvarint":  MustParseFuncSpec("(buf, _) -> next(buf)"),
+	"encoding/binary.AppendVarint":   MustParseFuncSpec("(buf, _) -> next(buf)"),
+	"(time.Time).AppendFormat":       MustParseFuncSpec("(_, b, _) -> next(b)"),
+}
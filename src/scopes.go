@@ -1,7 +1,8 @@
 package src
 
 import (
-	"fmt"
+	"go/ast"
+	"go/types"
 )
 
 const (
@@ -11,20 +12,98 @@ const (
 	AppendFuncName string = "append"
 )
 
+// DefaultNoReturnFuncs lists functions which never return control to the caller
+var DefaultNoReturnFuncs = map[string]struct{}{
+	"panic":                     {},
+	"os.Exit":                   {},
+	"runtime.Goexit":            {},
+	"log.Fatal":                 {},
+	"log.Fatalf":                {},
+	"log.Fatalln":               {},
+	"log.Panic":                 {},
+	"log.Panicf":                {},
+	"log.Panicln":               {},
+	"(*log.Logger).Fatal":       {},
+	"(*log.Logger).Fatalf":      {},
+	"(*log.Logger).Fatalln":     {},
+	"(*log.Logger).Panic":       {},
+	"(*log.Logger).Panicf":      {},
+	"(*log.Logger).Panicln":     {},
+	"(*testing.common).Fatal":   {},
+	"(*testing.common).Fatalf":  {},
+	"(*testing.common).FailNow": {},
+	"(*testing.common).Skip":    {},
+	"(*testing.common).Skipf":   {},
+	"(*testing.common).SkipNow": {},
+}
+
 type Scopes struct {
-	Funcs     map[string]FuncId
-	Vars      []map[string]VarId
-	LastVarId *VarId
+	Funcs map[string]FuncId
+	// NoReturnFuncs contains names (in the FuncName format) of functions which calls terminate the execution path
+	NoReturnFuncs map[string]struct{}
+	Vars          []map[string]VarId
+	LastVarId     *VarId
+	// Info is optional type information for the analyzed function
+	// If present - variables and functions are resolved through types.Object instead of the plain identifier names
+	Info    *types.Info
+	Objects map[types.Object]VarId
 }
 
 func NewScopes(funcs map[string]FuncId) Scopes {
 	vars := []map[string]VarId{make(map[string]VarId)}
 	lastVarId := VarId(0)
 	return Scopes{
-		Funcs:     funcs,
-		Vars:      vars,
-		LastVarId: &lastVarId,
+		Funcs:         funcs,
+		NoReturnFuncs: DefaultNoReturnFuncs,
+		Vars:          vars,
+		LastVarId:     &lastVarId,
+	}
+}
+
+func NewTypedScopes(funcs map[string]FuncId, info *types.Info) Scopes {
+	scopes := NewScopes(funcs)
+	scopes.Info = info
+	scopes.Objects = make(map[types.Object]VarId)
+	return scopes
+}
+
+// FuncName returns the name under which function object can be found in the Scopes.Funcs
+// Builtins are named as is (append, copy, ...), other functions are named with types.Func.FullName (slices.Insert, (*bytes.Buffer).Write, ...)
+func FuncName(obj types.Object) (string, bool) {
+	switch f := obj.(type) {
+	case *types.Builtin:
+		return f.Name(), true
+	case *types.Func:
+		return f.Origin().FullName(), true
 	}
+	return "", false
+}
+
+// MayHoldSlice returns false for types which values can't share slice backing array with other values (so they can be ignored by analysis)
+func MayHoldSlice(t types.Type) bool { return mayHoldSlice(t, make(map[types.Type]struct{})) }
+
+func mayHoldSlice(t types.Type, visited map[types.Type]struct{}) bool {
+	if _, ok := visited[t]; ok {
+		return false
+	}
+	visited[t] = struct{}{}
+	switch u := types.Unalias(t).(type) {
+	case *types.Slice, *types.TypeParam:
+		return true
+	case *types.Named:
+		return mayHoldSlice(u.Underlying(), visited)
+	case *types.Pointer:
+		return mayHoldSlice(u.Elem(), visited)
+	case *types.Array:
+		return mayHoldSlice(u.Elem(), visited)
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if mayHoldSlice(u.Field(i).Type(), visited) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func (s Scopes) NewVarId() VarId {
@@ -33,44 +112,141 @@ func (s Scopes) NewVarId() VarId {
 	return newId
 }
 
-func (s Scopes) TryGetFunc(name string) (FuncId, bool) {
+// TryGetCalledFunc resolves function called by the call expression
+// For the method value calls (obj.Method(...)) receiver expression is returned too: it must be passed to the function as the first input,
+// so specs of the methods describe receiver as the first argument (same as for the method expressions: (*T).Method(obj, ...))
+func (s Scopes) TryGetCalledFunc(fun ast.Expr) (FuncId, ast.Expr, bool) {
+	name, ok := s.CalledFuncName(fun)
+	if !ok {
+		return 0, nil, false
+	}
 	f, ok := s.Funcs[name]
-	return f, ok
+	if !ok {
+		return 0, nil, false
+	}
+	if s.Info != nil {
+		if selector, isSelector := uninstantiatedFunc(fun).(*ast.SelectorExpr); isSelector {
+			if selection, ok := s.Info.Selections[selector]; ok && selection.Kind() == types.MethodVal {
+				return f, selector.X, true
+			}
+		}
+	}
+	return f, nil, true
 }
 
-func (s Scopes) GetFunc(name string) FuncId {
-	f, ok := s.Funcs[name]
-	if !ok {
-		panic(fmt.Errorf("unable to find func '%v' in the scope", name))
+// uninstantiatedFunc strips parentheses and explicit instantiation of the generic function: (f[T, U]) -> f
+func uninstantiatedFunc(fun ast.Expr) ast.Expr {
+	fun = ast.Unparen(fun)
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		return ast.Unparen(f.X)
+	case *ast.IndexListExpr:
+		return ast.Unparen(f.X)
 	}
-	return f
+	return fun
 }
 
-func (s Scopes) GetVarOrBlank(name string) VarId {
-	for i := len(s.Vars) - 1; i >= 0; i-- {
-		if id, ok := s.Vars[i][name]; ok {
-			return id
+// CalledFuncName returns the name of the called function: f(...), f[T](...), pkg.f(...), obj.method(...) or T.method(obj, ...)
+// Methods can be resolved only with type information
+func (s Scopes) CalledFuncName(fun ast.Expr) (string, bool) {
+	fun = uninstantiatedFunc(fun)
+	if s.Info != nil {
+		switch f := fun.(type) {
+		case *ast.Ident:
+			return FuncName(s.Info.Uses[f])
+		case *ast.SelectorExpr:
+			return FuncName(s.Info.Uses[f.Sel])
 		}
+		return "", false
 	}
-	return BlankVarId
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name, s.GetVarOrBlank(f) == BlankVarId
+	case *ast.SelectorExpr:
+		if pkgIdent, ok := f.X.(*ast.Ident); ok && s.GetVarOrBlank(pkgIdent) == BlankVarId {
+			return pkgIdent.Name + "." + f.Sel.Name, true
+		}
+	}
+	return "", false
 }
 
-func (s Scopes) GetVar(name string) VarId {
-	if name == BlankVarName {
+// Conversion reports whether the call expression is a type conversion T(x) and whether its result shares components with x
+// Components are shared only if both types may hold slice (e.g. []byte(str) copies the string and any(s) hides the slice from the analysis)
+// Conversions can be recognized only with type information
+func (s Scopes) Conversion(call *ast.CallExpr) (shared bool, isConversion bool) {
+	if s.Info == nil || len(call.Args) != 1 {
+		return false, false
+	}
+	tv, ok := s.Info.Types[call.Fun]
+	if !ok || !tv.IsType() {
+		return false, false
+	}
+	return MayHoldSlice(tv.Type) && MayHoldSlice(s.Info.TypeOf(call.Args[0])), true
+}
+
+func (s Scopes) IsNoReturnCall(call *ast.CallExpr) bool {
+	name, ok := s.CalledFuncName(call.Fun)
+	if !ok {
+		return false
+	}
+	_, noReturn := s.NoReturnFuncs[name]
+	return noReturn
+}
+
+func (s Scopes) GetFunc(name string) (FuncId, bool) {
+	f, ok := s.Funcs[name]
+	return f, ok
+}
+
+func (s Scopes) GetVarOrBlank(ident *ast.Ident) VarId {
+	if s.Info != nil {
+		if id, ok := s.Objects[s.Info.ObjectOf(ident)]; ok {
+			return id
+		}
 		return BlankVarId
 	}
 	for i := len(s.Vars) - 1; i >= 0; i-- {
-		if id, ok := s.Vars[i][name]; ok {
+		if id, ok := s.Vars[i][ident.Name]; ok {
 			return id
 		}
 	}
-	panic(fmt.Errorf("variable '%v' not found", name))
+	return BlankVarId
 }
 
-func (s Scopes) CreateVar(name string) VarId {
+func (s Scopes) CreateVar(ident *ast.Ident) VarId {
+	if s.Info != nil {
+		return s.createVar(ident.Name, s.Info.ObjectOf(ident))
+	}
+	return s.createVar(ident.Name, nil)
+}
+
+// CreateImplicitVar creates variable which is declared implicitly for the node (e.g. symbolic variable in the type switch clause)
+func (s Scopes) CreateImplicitVar(ident *ast.Ident, node ast.Node) VarId {
+	if s.Info != nil {
+		return s.createVar(ident.Name, s.Info.Implicits[node])
+	}
+	return s.createVar(ident.Name, nil)
+}
+
+func (s Scopes) createVar(name string, obj types.Object) VarId {
 	if name == BlankVarName {
 		return BlankVarId
 	}
+	if s.Info != nil {
+		if obj == nil {
+			return BlankVarId
+		}
+		// redeclaration in the short variable declaration refers to the already existing variable
+		if id, ok := s.Objects[obj]; ok {
+			return id
+		}
+		newId := VarId(BlankVarId)
+		if MayHoldSlice(obj.Type()) {
+			newId = s.NewVarId()
+		}
+		s.Objects[obj] = newId
+		return newId
+	}
 	newId := s.NewVarId()
 	s.Vars[len(s.Vars)-1][name] = newId
 	return newId
@@ -78,8 +254,11 @@ func (s Scopes) CreateVar(name string) VarId {
 
 func (s Scopes) PushScope() Scopes {
 	return Scopes{
-		Funcs:     s.Funcs,
-		Vars:      append(s.Vars, make(map[string]VarId)),
-		LastVarId: s.LastVarId,
+		Funcs:         s.Funcs,
+		NoReturnFuncs: s.NoReturnFuncs,
+		Vars:          append(s.Vars, make(map[string]VarId)),
+		LastVarId:     s.LastVarId,
+		Info:          s.Info,
+		Objects:       s.Objects,
 	}
 }
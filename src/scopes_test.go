@@ -0,0 +1,60 @@
+package src
+
+import (
+	"go/ast"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gomakus/utils"
+)
+
+func TestMayHoldSlice(t *testing.T) {
+	intSlice := types.NewSlice(types.Typ[types.Int])
+	structWithSlice := types.NewStruct([]*types.Var{
+		types.NewField(0, nil, "n", types.Typ[types.Int], false),
+		types.NewField(0, nil, "items", intSlice, false),
+	}, nil)
+	require.True(t, MayHoldSlice(intSlice))
+	require.True(t, MayHoldSlice(structWithSlice))
+	require.True(t, MayHoldSlice(types.NewPointer(structWithSlice)))
+	require.True(t, MayHoldSlice(types.NewArray(intSlice, 2)))
+	require.False(t, MayHoldSlice(types.Typ[types.String]))
+	require.False(t, MayHoldSlice(types.NewMap(types.Typ[types.String], intSlice)))
+	require.False(t, MayHoldSlice(types.NewStruct([]*types.Var{types.NewField(0, nil, "n", types.Typ[types.Int], false)}, nil)))
+}
+
+func TestTypedScopesIgnoreNonSliceVars(t *testing.T) {
+	fset, file, info := utils.MustGenTypedSrc(`package main
+type Pair struct{ Left, Right []int }
+func f(n int, s string, items []int, pair *Pair) {
+	m, t := n, s
+	_, _ = m, t
+}`)
+	scopes := NewTypedScopes(DefaultFuncs, info)
+	MustExecutionFromFunc(scopes, fset, utils.MustExtractFunc(file, "f"))
+	tracked := make(map[string]bool)
+	for obj, varId := range scopes.Objects {
+		tracked[obj.Name()] = varId != BlankVarId
+	}
+	require.Equal(t, map[string]bool{"n": false, "s": false, "items": true, "pair": true, "m": false, "t": false}, tracked)
+}
+
+func TestTypedScopesConversion(t *testing.T) {
+	_, file, info := utils.MustGenTypedSrc(`package main
+type names []string
+func f(items []string, s string) {
+	_, _, _, _ = names(items), []byte(s), any(items), len(items)
+}`)
+	scopes := NewTypedScopes(DefaultFuncs, info)
+	var results [][2]bool
+	ast.Inspect(utils.MustExtractFunc(file, "f"), func(node ast.Node) bool {
+		if call, ok := node.(*ast.CallExpr); ok {
+			shared, isConversion := scopes.Conversion(call)
+			results = append(results, [2]bool{shared, isConversion})
+		}
+		return true
+	})
+	require.Equal(t, [][2]bool{{true, true}, {false, true}, {false, true}, {false, false}}, results)
+}
@@ -1,11 +1,14 @@
 package src
 
 import (
-	"github.com/sivukhin/gomakus/utils"
+	"slices"
+	"sort"
 )
 
 type SimplificationContext struct {
 	Funcs map[FuncId]FuncSpec
+	// Budget bounds the simplification: if it is exceeded, simplified execution is incomplete
+	Budget *Budget
 }
 
 func SelectAssignOps(context SimplificationContext, execution Execution) []AssignSelectorOp {
@@ -20,14 +23,8 @@ func SelectAssignOps(context SimplificationContext, execution Execution) []Assig
 				if !ok {
 					continue
 				}
-				for _, output := range funcSpec.Outputs {
-					for i, outputComponent := range output {
-						assigns = append(assigns, AssignSelectorOp{
-							// todo (sivukhin, 2024-03-23): very dirty hack!
-							FromSelector: op.Inputs[outputComponent.InputRef.ArgIndex][outputComponent.InputRef.SelectorIndex].VarSelector,
-							ToSelector:   VarSelector{VarId: op.Outputs[i], Selector: outputComponent.OutputPath},
-						})
-					}
+				for _, assign := range funcSpecAssigns(funcSpec, op) {
+					assigns = append(assigns, assign.AssignSelectorOp)
 				}
 			}
 		}
@@ -35,22 +32,111 @@ func SelectAssignOps(context SimplificationContext, execution Execution) []Assig
 	return assigns
 }
 
+type funcSpecAssign struct {
+	AssignSelectorOp
+	GenChange GenChangeType
+}
+
+// funcSpecAssigns expands function call into assignments from the input components to the output components according to the spec
+// Updates of the spec are assigned back to the variables passed as the updated inputs after the outputs
+func funcSpecAssigns(funcSpec FuncSpec, operation UseSelectorsOp) []funcSpecAssign {
+	// operation can have fewer outputs than spec when multi-value call is passed as an argument: f(g())
+	// and more outputs than spec if the user-defined spec doesn't match the signature: extra outputs are fresh values then
+	assigns := make([]funcSpecAssign, 0)
+	for i, output := range operation.Outputs {
+		if i >= len(funcSpec.Outputs) {
+			assigns = append(assigns, funcSpecAssign{
+				AssignSelectorOp: AssignSelectorOp{FromSelector: VarSelector{VarId: BlankVarId}, ToSelector: VarSelector{VarId: output}},
+				GenChange:        SameGen,
+			})
+			continue
+		}
+		for _, outputRef := range funcSpec.Outputs[i] {
+			assigns = append(assigns, funcSpecRefAssigns(funcSpec, operation, outputRef, VarSelector{VarId: output, Selector: outputRef.OutputPath})...)
+		}
+	}
+	argIndexes := make([]int, 0, len(funcSpec.Updates))
+	for argIndex := range funcSpec.Updates {
+		argIndexes = append(argIndexes, argIndex)
+	}
+	sort.Ints(argIndexes)
+	for _, argIndex := range argIndexes {
+		if argIndex >= len(operation.Inputs) {
+			continue
+		}
+		for _, outputRef := range funcSpec.Updates[argIndex] {
+			// only variables can be updated: components of the composite literals passed as the argument are not visible to the caller
+			for _, inputEmbed := range operation.Inputs[argIndex] {
+				if target, ok := inputEmbed.SelectPath(outputRef.OutputPath); ok && len(target.Path) == 0 {
+					assigns = append(assigns, funcSpecRefAssigns(funcSpec, operation, outputRef, target.VarSelector)...)
+				}
+			}
+		}
+	}
+	return assigns
+}
+
+// funcSpecRefAssigns assigns the input component referenced by the output ref to the target selector (or fresh value if there is no such component)
+func funcSpecRefAssigns(funcSpec FuncSpec, operation UseSelectorsOp, outputRef FuncOutputRef, toSelector VarSelector) []funcSpecAssign {
+	var assigns []funcSpecAssign
+	inputRef := outputRef.InputRef
+	if inputRef.ArgIndex != BlankVarId && inputRef.ArgIndex < len(operation.Inputs) {
+		inputSelector := funcSpec.Inputs[inputRef.ArgIndex][inputRef.SelectorIndex].Selector
+		for _, inputEmbed := range operation.Inputs[inputRef.ArgIndex] {
+			selectedEmbed, ok := inputEmbed.SelectPath(inputSelector)
+			if !ok {
+				continue
+			}
+			assigns = append(assigns, funcSpecAssign{
+				AssignSelectorOp: AssignSelectorOp{
+					FromSelector: selectedEmbed.VarSelector,
+					ToSelector:   VarSelector{VarId: toSelector.VarId, Selector: append(append(Path{}, toSelector.Selector...), selectedEmbed.Path...)},
+				},
+				GenChange: outputRef.GenChange,
+			})
+		}
+	}
+	if len(assigns) == 0 {
+		assigns = append(assigns, funcSpecAssign{
+			AssignSelectorOp: AssignSelectorOp{FromSelector: VarSelector{VarId: BlankVarId}, ToSelector: toSelector},
+			GenChange:        outputRef.GenChange,
+		})
+	}
+	return assigns
+}
+
 func SimplifyExecution(context SimplificationContext, execution Execution) (Execution, map[ExecutionPoint]ExecutionPoint) {
-	assigns := SelectAssignOps(context, execution)
+	simplified, simplification := newSimplification(context, execution)
+	return simplified, simplification.simplifiedToOriginal
+}
+
+// newSimplification simplifies execution and returns context which holds mapping between original and simplified variables & points
+func newSimplification(context SimplificationContext, execution Execution) (Execution, *simplificationContext) {
 	simplification := &simplificationContext{
+		budget:                   context.Budget,
 		funcs:                    context.Funcs,
-		factorization:            FactorizeAssignments(assigns),
+		factorization:            make(FactorizationRules),
 		varSelectorCollection:    make(varSelectorCollection),
 		executionPointCollection: make(executionPointCollection),
 		visited:                  make(map[ExecutionPoint]struct{}),
 		simplifiedToOriginal:     make(map[ExecutionPoint]ExecutionPoint),
 	}
 	builder := NewExecutionBuilder(nil)
+	if context.Budget.points(execution) {
+		return builder.Build(), simplification
+	}
+	simplification.factorization = factorizeAssignments(context.Budget, SelectAssignOps(context, execution))
+	if context.Budget.Err() != nil {
+		return builder.Build(), simplification
+	}
 	simplification.simplifyExecution(builder, execution, execution.RootPoint)
-	return builder.Build(), simplification.simplifiedToOriginal
+	simplified := builder.Build()
+	context.Budget.points(simplified)
+	return simplified, simplification
 }
 
 type simplificationContext struct {
+	budget                   *Budget
 	funcs                    map[FuncId]FuncSpec
 	factorization            FactorizationRules
 	varSelectorCollection    varSelectorCollection
@@ -61,7 +147,7 @@ type simplificationContext struct {
 
 func (c *simplificationContext) simplifyExecution(builder ExecutionBuilder, execution Execution, point ExecutionPoint) {
 	_, ok := c.visited[point]
-	if ok {
+	if ok || c.budget.step() {
 		return
 	}
 	c.visited[point] = struct{}{}
@@ -77,60 +163,11 @@ func (c *simplificationContext) simplifyExecution(builder ExecutionBuilder, exec
 		toPoint := c.executionPointCollection.AcquireOrGet(builder, transition.ToPoint)
 		switch operation := transition.Operation.(type) {
 		case AssignSelectorOp:
-			if operation.FromSelector.VarId == BlankVarId && operation.ToSelector.VarId != BlankVarId {
-				toSelectors := c.factorization.FactorizeSelector(operation.ToSelector)
-				for i := range toSelectors {
-					fromVar := c.varSelectorCollection.IntroduceVarOrGet(VarSelector{VarId: BlankVarId})
-					toVar := c.varSelectorCollection.IntroduceVarOrGet(toSelectors[i])
-
-					builder = builder.ApplyNext(AssignVarOp{FromVarId: fromVar, ToVarId: toVar})
-					c.simplifiedToOriginal[builder.CurrentPoint] = transition.ToPoint
-				}
-			} else if operation.FromSelector.VarId != BlankVarId && operation.ToSelector.VarId == BlankVarId {
-				fromSelectors := c.factorization.FactorizeSelector(operation.FromSelector)
-				for i := range fromSelectors {
-					fromVar := c.varSelectorCollection.IntroduceVarOrGet(fromSelectors[i])
-					toVar := c.varSelectorCollection.IntroduceVarOrGet(VarSelector{VarId: BlankVarId})
-
-					builder = builder.ApplyNext(AssignVarOp{FromVarId: fromVar, ToVarId: toVar})
-					c.simplifiedToOriginal[builder.CurrentPoint] = transition.ToPoint
-				}
-			} else {
-				fromSelectors := c.factorization.FactorizeSelector(operation.FromSelector)
-				toSelectors := c.factorization.FactorizeSelector(operation.ToSelector)
-				utils.Assertf(len(fromSelectors) == len(toSelectors), "inconsistent assignment operator factorization: from=%+v, to=%+v", fromSelectors, toSelectors)
-				for i := range fromSelectors {
-					fromVar := c.varSelectorCollection.IntroduceVarOrGet(fromSelectors[i])
-					toVar := c.varSelectorCollection.IntroduceVarOrGet(toSelectors[i])
-
-					builder = builder.ApplyNext(AssignVarOp{FromVarId: fromVar, ToVarId: toVar})
-					c.simplifiedToOriginal[builder.CurrentPoint] = transition.ToPoint
-				}
-			}
+			builder = c.simplifyAssign(builder, operation, SameGen, transition.ToPoint)
 		case UseSelectorsOp:
 			if funcSpec, ok := c.funcs[operation.FuncId]; ok {
-				utils.Assertf(len(funcSpec.Outputs) == len(operation.Outputs), "spec outputs must have same length as operation outputs: %v != %v", len(funcSpec.Outputs), len(operation.Outputs))
-				for i, output := range operation.Outputs {
-					funcOutputComponents := funcSpec.Outputs[i]
-					for _, funcInputRef := range funcOutputComponents {
-						var fromSelector, toSelector VarSelector
-						if funcInputRef.InputRef.ArgIndex == BlankVarId {
-							fromSelector = VarSelector{VarId: BlankVarId}
-							toSelector = VarSelector{VarId: output, Selector: funcInputRef.OutputPath}
-						} else {
-							inputSelector := funcSpec.Inputs[funcInputRef.InputRef.ArgIndex][funcInputRef.InputRef.SelectorIndex].Selector
-							utils.Assertf(len(inputSelector) == 0, "only degenerate selectors are supported: %#v", inputSelector)
-
-							for _, inputEmbed := range operation.Inputs[funcInputRef.InputRef.ArgIndex] {
-								fromSelector = VarSelector{VarId: inputEmbed.VarSelector.VarId, Selector: inputEmbed.VarSelector.Selector}
-								toSelector = VarSelector{VarId: output, Selector: append(append(Path{}, funcInputRef.OutputPath...), inputEmbed.Path...)}
-							}
-						}
-						fromVar := c.varSelectorCollection.IntroduceVarOrGet(fromSelector)
-						toVar := c.varSelectorCollection.IntroduceVarOrGet(toSelector)
-						builder = builder.ApplyNext(AssignVarOp{FromVarId: fromVar, ToVarId: toVar, GenChange: funcInputRef.GenChange})
-						c.simplifiedToOriginal[builder.CurrentPoint] = transition.ToPoint
-					}
+				for _, assign := range funcSpecAssigns(funcSpec, operation) {
+					builder = c.simplifyAssign(builder, assign.AssignSelectorOp, assign.GenChange, transition.ToPoint)
 				}
 			} else {
 				for _, output := range operation.Outputs {
@@ -143,6 +180,58 @@ func (c *simplificationContext) simplifyExecution(builder ExecutionBuilder, exec
 	}
 }
 
+// simplifyAssign factorizes both sides of the assignment and emits primitive assignment for every pair of the factorized components
+func (c *simplificationContext) simplifyAssign(builder ExecutionBuilder, operation AssignSelectorOp, genChange GenChangeType, original ExecutionPoint) ExecutionBuilder {
+	var fromSelectors, toSelectors []VarSelector
+	if operation.FromSelector.VarId == BlankVarId && operation.ToSelector.VarId == BlankVarId {
+		return builder
+	} else if operation.FromSelector.VarId == BlankVarId {
+		toSelectors = c.factorization.FactorizeSelector(operation.ToSelector)
+		fromSelectors = make([]VarSelector, len(toSelectors))
+		for i := range fromSelectors {
+			fromSelectors[i] = VarSelector{VarId: BlankVarId}
+		}
+	} else if operation.ToSelector.VarId == BlankVarId {
+		fromSelectors = c.factorization.FactorizeSelector(operation.FromSelector)
+		toSelectors = make([]VarSelector, len(fromSelectors))
+		for i := range toSelectors {
+			toSelectors[i] = VarSelector{VarId: BlankVarId}
+		}
+	} else {
+		fromSelectors = c.factorization.FactorizeSelector(operation.FromSelector)
+		toSelectors = c.factorization.FactorizeSelector(operation.ToSelector)
+		if len(fromSelectors) != len(toSelectors) {
+			fromSelectors = matchSelectors(operation, fromSelectors, toSelectors)
+		}
+	}
+	for i := range fromSelectors {
+		fromVar := c.varSelectorCollection.IntroduceVarOrGet(fromSelectors[i])
+		toVar := c.varSelectorCollection.IntroduceVarOrGet(toSelectors[i])
+
+		builder = builder.ApplyNext(AssignVarOp{FromVarId: fromVar, ToVarId: toVar, GenChange: genChange})
+		c.simplifiedToOriginal[builder.CurrentPoint] = original
+	}
+	return builder
+}
+
+// matchSelectors finds source component for every target component of the assignment which sides are factorized differently
+// (e.g. paths truncated by maxFactorizationPathLength or cycles through the same variable which aren't factorized):
+// component which path is the prefix of the source path of the target (or extends it) is used, and target without such component gets fresh value
+func matchSelectors(operation AssignSelectorOp, fromSelectors, toSelectors []VarSelector) []VarSelector {
+	matched := make([]VarSelector, len(toSelectors))
+	for i, to := range toSelectors {
+		path := append(slices.Clone(operation.FromSelector.Selector), to.Selector[len(operation.ToSelector.Selector):]...)
+		matched[i] = VarSelector{VarId: BlankVarId}
+		for _, from := range fromSelectors {
+			if hasPrefix(path, from.Selector) || hasPrefix(from.Selector, path) {
+				matched[i] = from
+				break
+			}
+		}
+	}
+	return matched
+}
+
 type varSelectorCollection map[string]int
 
 func (c varSelectorCollection) IntroduceVarOrGet(selector VarSelector) VarId {
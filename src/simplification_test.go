@@ -16,7 +16,7 @@ func TestSimplification(t *testing.T) {
 	}
 	return ret
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
@@ -44,7 +44,7 @@ func TestSimplification2(t *testing.T) {
 
 	return path
 }`)
-	execution := ExecutionFromFunc(NewScopes(map[string]FuncId{
+	execution := MustExecutionFromFunc(NewScopes(map[string]FuncId{
 		SliceFuncName:  SliceFuncId,
 		AppendFuncName: AppendFuncId,
 	}), fset, funcDecl)
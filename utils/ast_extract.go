@@ -20,3 +20,12 @@ func MustExtractLabeledStatement[T ast.Node](label string, nodes ...T) ast.Node
 	}
 	return labeled
 }
+
+func MustExtractFunc(file *ast.File, name string) *ast.FuncDecl {
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Name.Name == name {
+			return funcDecl
+		}
+	}
+	panic(fmt.Errorf("unable to find func '%v'", name))
+}
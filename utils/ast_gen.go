@@ -3,8 +3,10 @@ package utils
 import (
 	"fmt"
 	"go/ast"
+	"go/importer"
 	"go/parser"
 	"go/token"
+	"go/types"
 )
 
 func MustGenStatements(statements string) (*token.FileSet, []ast.Stmt) {
@@ -19,9 +21,26 @@ func MustGenFunc(src string) (*token.FileSet, *ast.FuncDecl) {
 
 func MustGenSrc(src string) (*token.FileSet, *ast.File) {
 	fset := token.NewFileSet()
-	fileAst, err := parser.ParseFile(fset, "", src, parser.AllErrors)
+	fileAst, err := parser.ParseFile(fset, "", src, parser.AllErrors|parser.ParseComments)
 	if err != nil {
 		panic(fmt.Errorf("src parsing failed: %w", err))
 	}
 	return fset, fileAst
 }
+
+func MustGenTypedSrc(src string) (*token.FileSet, *ast.File, *types.Info) {
+	fset, fileAst := MustGenSrc(src)
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Instances:  make(map[*ast.Ident]types.Instance),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	config := types.Config{Importer: importer.Default()}
+	if _, err := config.Check(fileAst.Name.Name, fset, []*ast.File{fileAst}, info); err != nil {
+		panic(fmt.Errorf("src type checking failed: %w", err))
+	}
+	return fset, fileAst, info
+}
@@ -2,6 +2,8 @@ package utils
 
 import (
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestGenStatements(t *testing.T) {
@@ -32,3 +34,13 @@ return a + b
 }`)
 	t.Log(fset, srcAst)
 }
+
+func TestGenTypedSrc(t *testing.T) {
+	_, srcAst, info := MustGenTypedSrc(`package main
+import "slices"
+func clone(a []int) []int {
+return slices.Clone(a)
+}`)
+	require.NotEmpty(t, info.Uses)
+	require.Equal(t, "clone", MustExtractFunc(srcAst, "clone").Name.Name)
+}